@@ -0,0 +1,238 @@
+package mannequin
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+const (
+	backupsPath   = "backups"
+	backupPrefix  = "mnqn-"
+	backupExt     = ".tar.gz"
+	backupTimeFmt = "20060102-150405.000000"
+)
+
+// BackupsFolderPath returns the path of the folder the configuration backups are stored in.
+// Backups are stored in the state folder, so they survive the implosion.
+func BackupsFolderPath() (string, error) {
+	dir, err := StateFolderPath()
+	if err != nil {
+		return "", err
+	}
+
+	return filepath.Join(dir, backupsPath), nil
+}
+
+// BackupConfig archives the configuration folder into the timestamped backup.
+// Returns the path of the backup.
+func BackupConfig() (string, error) {
+	src, err := ConfigFolderPath()
+	if err != nil {
+		return "", err
+	}
+	dir, err := BackupsFolderPath()
+	if err != nil {
+		return "", err
+	}
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return "", fmt.Errorf("couldn't create backups folder: %s", err)
+	}
+
+	path := filepath.Join(dir, backupPrefix+time.Now().Format(backupTimeFmt)+backupExt)
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0600)
+	if err != nil {
+		return "", err
+	}
+
+	if err := archive(f, src); err != nil {
+		f.Close()       // nolint: errcheck
+		os.Remove(path) // nolint: errcheck
+		return "", fmt.Errorf("couldn't archive %s: %s", src, err)
+	}
+
+	return path, f.Close()
+}
+
+// Backups returns the paths of the available backups, the latest one is the last.
+func Backups() ([]string, error) {
+	dir, err := BackupsFolderPath()
+	if err != nil {
+		return nil, err
+	}
+
+	res, err := filepath.Glob(filepath.Join(dir, backupPrefix+"*"+backupExt))
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(res)
+
+	return res, nil
+}
+
+// FindBackup by it's name (with or without extension) or path.
+func FindBackup(name string) (string, error) {
+	bs, err := Backups()
+	if err != nil {
+		return "", err
+	}
+
+	for _, b := range bs {
+		base := filepath.Base(b)
+		if b == name || base == name || strings.TrimSuffix(base, backupExt) == name {
+			return b, nil
+		}
+	}
+	if _, err := os.Stat(name); err == nil {
+		return name, nil
+	}
+
+	return "", fmt.Errorf("backup \"%s\" is not found", name)
+}
+
+// RestoreBackup replaces the configuration folder with the one of the backup.
+// Existing configuration folder is backed up first.
+// Returns the path of the backup of the replaced configuration (if any).
+func RestoreBackup(path string) (string, error) {
+	dst, err := ConfigFolderPath()
+	if err != nil {
+		return "", err
+	}
+
+	// backup is extracted next to the configuration folder first,
+	// so the broken backup doesn't destroy the current configuration.
+	if err := os.MkdirAll(filepath.Dir(filepath.Clean(dst)), 0700); err != nil {
+		return "", err
+	}
+	tmp, err := ioutil.TempDir(filepath.Dir(filepath.Clean(dst)), ".mnqn-restore")
+	if err != nil {
+		return "", err
+	}
+	defer os.RemoveAll(tmp) // nolint: errcheck
+
+	f, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	if err := extract(f, tmp); err != nil {
+		return "", fmt.Errorf("couldn't extract %s: %s", path, err)
+	}
+	if _, err := os.Stat(filepath.Join(tmp, configFile)); err != nil {
+		return "", fmt.Errorf("%s has no %s", path, configFile)
+	}
+
+	var replaced string
+	if _, err := os.Stat(dst); err == nil {
+		if replaced, err = BackupConfig(); err != nil {
+			return "", fmt.Errorf("couldn't back up current configuration: %s", err)
+		}
+		if err := os.RemoveAll(dst); err != nil {
+			return "", err
+		}
+	}
+
+	return replaced, os.Rename(tmp, filepath.Clean(dst))
+}
+
+// archive the dir to w as tar.gz with the paths relative to the dir.
+func archive(w io.Writer, dir string) error {
+	gw := gzip.NewWriter(w)
+	tw := tar.NewWriter(gw)
+
+	err := filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+
+		rel, err := filepath.Rel(dir, path)
+		if err != nil || rel == "." {
+			return err
+		}
+
+		h, err := tar.FileInfoHeader(info, "")
+		if err != nil {
+			return err
+		}
+		h.Name = filepath.ToSlash(rel)
+		if err := tw.WriteHeader(h); err != nil {
+			return err
+		}
+		if !info.Mode().IsRegular() {
+			return nil
+		}
+
+		f, err := os.Open(path)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+
+		_, err = io.Copy(tw, f)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+
+	if err := tw.Close(); err != nil {
+		return err
+	}
+
+	return gw.Close()
+}
+
+// extract tar.gz from r to the dir.
+func extract(r io.Reader, dir string) error {
+	gr, err := gzip.NewReader(r)
+	if err != nil {
+		return err
+	}
+	tr := tar.NewReader(gr)
+
+	for {
+		h, err := tr.Next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		path := filepath.Join(dir, filepath.FromSlash(h.Name))
+		if !strings.HasPrefix(path, filepath.Clean(dir)+string(os.PathSeparator)) {
+			return errors.New("backup contains paths outside of the configuration folder")
+		}
+
+		switch h.Typeflag {
+		case tar.TypeDir:
+			if err := os.MkdirAll(path, os.FileMode(h.Mode)); err != nil {
+				return err
+			}
+		case tar.TypeReg:
+			if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+				return err
+			}
+			f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, os.FileMode(h.Mode))
+			if err != nil {
+				return err
+			}
+			if _, err := io.Copy(f, tr); err != nil {
+				f.Close() // nolint: errcheck
+				return err
+			}
+			if err := f.Close(); err != nil {
+				return err
+			}
+		}
+	}
+}
@@ -0,0 +1,121 @@
+package mannequin
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestArchiveExtract(t *testing.T) {
+	src, err := ioutil.TempDir("", "backup")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(src)
+
+	files := map[string]string{
+		"config.yaml":          "version: v0.1.0\n",
+		"secrets.key":          "key\n",
+		"profiles/dev/ns.yaml": "namespace: dev\n",
+	}
+	for name, body := range files {
+		path := filepath.Join(src, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+			t.Fatal(err)
+		}
+		if err := ioutil.WriteFile(path, []byte(body), 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := os.Mkdir(filepath.Join(src, "empty"), 0700); err != nil {
+		t.Fatal(err)
+	}
+
+	var buf bytes.Buffer
+	if err := archive(&buf, src); err != nil {
+		t.Fatal(err)
+	}
+
+	dst, err := ioutil.TempDir("", "backup")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dst)
+
+	if err := extract(&buf, dst); err != nil {
+		t.Fatal(err)
+	}
+
+	for name, body := range files {
+		path := filepath.Join(dst, filepath.FromSlash(name))
+		data, err := ioutil.ReadFile(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(data) != body {
+			t.Errorf("%s: expected \"%s\", got \"%s\"", name, body, data)
+		}
+
+		info, err := os.Stat(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if info.Mode().Perm() != 0600 {
+			t.Errorf("%s: expected mode 0600, got %s", name, info.Mode().Perm())
+		}
+	}
+	if info, err := os.Stat(filepath.Join(dst, "empty")); err != nil || !info.IsDir() {
+		t.Errorf("empty folder is not extracted: %v", err)
+	}
+}
+
+func TestExtractOutside(t *testing.T) {
+	tests := []string{
+		"../evil",
+		"nested/../../evil",
+		"..",
+	}
+
+	for _, name := range tests {
+		t.Run(name, func(t *testing.T) {
+			parent, err := ioutil.TempDir("", "backup")
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer os.RemoveAll(parent)
+
+			dst := filepath.Join(parent, "config")
+			if err := os.Mkdir(dst, 0700); err != nil {
+				t.Fatal(err)
+			}
+
+			var buf bytes.Buffer
+			gw := gzip.NewWriter(&buf)
+			tw := tar.NewWriter(gw)
+			body := []byte("evil")
+			if err := tw.WriteHeader(&tar.Header{Name: name, Mode: 0600, Size: int64(len(body)), Typeflag: tar.TypeReg}); err != nil {
+				t.Fatal(err)
+			}
+			if _, err := tw.Write(body); err != nil {
+				t.Fatal(err)
+			}
+			if err := tw.Close(); err != nil {
+				t.Fatal(err)
+			}
+			if err := gw.Close(); err != nil {
+				t.Fatal(err)
+			}
+
+			if err := extract(&buf, dst); err == nil {
+				t.Fatal("expected paths outside of the folder to be rejected")
+			}
+			if _, err := os.Stat(filepath.Join(parent, "evil")); !os.IsNotExist(err) {
+				t.Errorf("file is written outside of the folder: %v", err)
+			}
+		})
+	}
+}
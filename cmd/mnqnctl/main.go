@@ -2,24 +2,52 @@ package main
 
 import (
 	"bufio"
+	"flag"
 	"fmt"
 	"os"
 	"strings"
 
 	"github.com/kostkobv/mannequin"
 	"github.com/kostkobv/mannequin/feat"
+	"github.com/kostkobv/mannequin/feat/completion"
+	"github.com/kostkobv/mannequin/feat/config"
+	"github.com/kostkobv/mannequin/feat/config/paths"
+	"github.com/kostkobv/mannequin/feat/config/schema"
+	"github.com/kostkobv/mannequin/feat/config/validate"
 	"github.com/kostkobv/mannequin/feat/deploy"
 	"github.com/kostkobv/mannequin/feat/deploy/latest"
+	"github.com/kostkobv/mannequin/feat/down"
 	"github.com/kostkobv/mannequin/feat/implode"
 	"github.com/kostkobv/mannequin/feat/initproject"
+	"github.com/kostkobv/mannequin/feat/kcontext"
+	"github.com/kostkobv/mannequin/feat/profile"
+	"github.com/kostkobv/mannequin/feat/projects"
+	"github.com/kostkobv/mannequin/feat/projects/list"
+	"github.com/kostkobv/mannequin/feat/projects/move"
+	"github.com/kostkobv/mannequin/feat/projects/prune"
+	"github.com/kostkobv/mannequin/feat/projects/remove"
+	"github.com/kostkobv/mannequin/feat/projects/rename"
+	"github.com/kostkobv/mannequin/feat/projects/scan"
+	"github.com/kostkobv/mannequin/feat/restore"
+	"github.com/kostkobv/mannequin/feat/secrets"
+	"github.com/kostkobv/mannequin/feat/secrets/edit"
+	"github.com/kostkobv/mannequin/feat/secrets/set"
 	"github.com/kostkobv/mannequin/feat/version"
 )
 
 var out = os.Stdout
 
 func main() {
+	args := os.Args[1:]
+
+	// configuration path is required before the features are parsed.
+	cfgPath, custom := feat.LookupFlag(args, "config")
+
+	// completion works before the client is initialised and never prompts.
+	completing := len(args) != 0 && (args[0] == completion.Name || args[0] == completion.CompleteName)
+
 	// check if initialised first.
-	if err := mannequin.CheckInited(); err != nil {
+	if err := mannequin.CheckInited(); err != nil && !custom && !completing {
 		fmt.Fprintf(out, "Couldn't execute the command: %s\n", err)
 		fmt.Fprintln(out, "Mannequin is not initialised yet.")
 		fmt.Fprintln(out, "Do you want to initialise the client now? (Y/n):")
@@ -44,35 +72,66 @@ func main() {
 		}
 	}
 
-	cfgPath, err := mannequin.ConfigPath()
+	if !custom {
+		var err error
+		if cfgPath, err = mannequin.ConfigPath(); err != nil {
+			fmt.Fprintf(out, "Couldn't get the configuration path: %s\n", err)
+			os.Exit(2)
+			return
+		}
+	}
+	// configuration is replaced on save, so the file is not kept open.
+	var cfg *mannequin.Config
+	cfgFile, err := os.Open(cfgPath)
+	switch {
+	case err != nil && completing:
+		// clean configuration is used.
+	case err != nil:
+		fmt.Fprintf(out, "Couldn't open the configration file: %s\n", err)
+		os.Exit(2)
+		return
+	default:
+		c, err := mannequin.NewConfigFromFile(cfgFile)
+		cfgFile.Close() // nolint: errcheck
+		if err != nil {
+			fmt.Fprintf(out, "Couldn't read the configration: %s\n", err)
+			os.Exit(2)
+			return
+		}
+		cfg = &c
+	}
+	mnqn, err := mannequin.New(os.Stdout, cfg)
 	if err != nil {
-		fmt.Fprintf(out, "Couldn't get the configuration path: %s\n", err)
+		fmt.Fprintf(out, "Couldn't parse the configuration: %s\n", err)
 		os.Exit(2)
 		return
 	}
-	cfgFile, err := os.OpenFile(cfgPath, os.O_RDWR, 0775)
+	mnqn.ConfigPath = cfgPath
+
+	deployctl, err := deploy.New(latest.New())
 	if err != nil {
-		fmt.Fprintf(out, "Couldn't open the configration file: %s\n", err)
+		fmt.Fprintf(out, "Couldn't initialize deploy features: %s\n", err)
 		os.Exit(2)
 		return
 	}
-	defer cfgFile.Close()
-	cfg, err := mannequin.NewConfigFromFile(cfgFile)
+
+	secretsctl, err := secrets.New(set.New(), edit.New())
 	if err != nil {
-		fmt.Fprintf(out, "Couldn't read the configration: %s\n", err)
+		fmt.Fprintf(out, "Couldn't initialize secrets features: %s\n", err)
 		os.Exit(2)
 		return
 	}
-	mnqn, err := mannequin.New(os.Stdout, &cfg)
+
+	configctl, err := config.New(validate.New(), schema.New(), paths.New())
 	if err != nil {
-		fmt.Fprintf(out, "Couldn't parse the configuration: %s\n", err)
+		fmt.Fprintf(out, "Couldn't initialize config features: %s\n", err)
 		os.Exit(2)
 		return
 	}
 
-	deployctl, err := deploy.New(latest.New())
+	projectsctl, err := projects.New(list.New(), remove.New(), move.New(), rename.New(), prune.New(), scan.New())
 	if err != nil {
-		fmt.Fprintf(out, "Couldn't initialize deploy features: %s\n", err)
+		fmt.Fprintf(out, "Couldn't initialize projects features: %s\n", err)
 		os.Exit(2)
 		return
 	}
@@ -80,8 +139,15 @@ func main() {
 	// register available features.
 	mnqnctl, err := feat.NewMnqnctlFeats(
 		deployctl,
+		down.New(),
 		initproject.New(),
 		implode.New(),
+		restore.New(),
+		secretsctl,
+		profile.New(),
+		kcontext.New(),
+		configctl,
+		projectsctl,
 		version.New(),
 	)
 	if err != nil {
@@ -89,23 +155,32 @@ func main() {
 		os.Exit(2)
 		return
 	}
+	// completion is generated from every registered feature including itself.
+	if err := mnqnctl.Register(completion.New(mnqnctl)); err != nil {
+		fmt.Fprintf(out, "Couldn't initialize completion: %s\n", err)
+		os.Exit(2)
+		return
+	}
+	if err := mnqnctl.Register(completion.NewComplete()); err != nil {
+		fmt.Fprintf(out, "Couldn't initialize completion: %s\n", err)
+		os.Exit(2)
+		return
+	}
 
-	// execute the command.
-	if err := mnqnctl.Do(mnqn, params(os.Args[1:])...); err != nil {
-		fmt.Fprintf(out, "Couldn't execute: %s", err)
+	// parse the global flags placed before the command.
+	args, err = feat.Parse(&mnqn, mnqnctl, args)
+	switch {
+	case err == flag.ErrHelp:
+		args = nil
+	case err != nil:
+		fmt.Fprintf(out, "Couldn't parse the arguments: %s\n", err)
 		os.Exit(1)
+		return
 	}
-}
 
-func params(ps []string) []string {
-	var res []string
-	for _, p := range ps {
-		if strings.HasPrefix(p, "-") {
-			continue
-		}
-
-		res = append(res, p)
+	// execute the command.
+	if err := mnqnctl.Do(mnqn, args...); err != nil {
+		fmt.Fprintf(out, "Couldn't execute: %s\n", err)
+		os.Exit(1)
 	}
-
-	return res
 }
@@ -1,9 +1,12 @@
 package mannequin
 
 import (
+	"crypto/rand"
+	"encoding/hex"
 	"errors"
 	"fmt"
 	"os"
+	"path/filepath"
 
 	"gopkg.in/yaml.v2"
 )
@@ -11,8 +14,16 @@ import (
 // Config represents data that is persisted and used later.
 type Config struct {
 	Version  string    `yaml:"version,flow"`
+	Context  string    `yaml:"context,omitempty,flow"`
 	Projects []Project `yaml:"projects,flow"`
-	file     *os.File
+	// AllowedContexts are the remote contexts that are allowed to be deployed to.
+	AllowedContexts []string `yaml:"allowed_contexts,omitempty,flow"`
+	// Install is the random ID of the installation the deployed objects are labeled with,
+	// so the installations sharing the cluster don't prune each other's objects.
+	Install string `yaml:"install,omitempty"`
+	path    string
+	// migratedFrom is the version of the file the Config is migrated from on load.
+	migratedFrom string
 }
 
 // NewConfig is a constructor for Config.
@@ -30,35 +41,227 @@ func NewConfigFromFile(file *os.File) (Config, error) {
 		return Config{}, errors.New("file is required")
 	}
 
-	c := Config{file: file}
-	if err := yaml.NewDecoder(file).Decode(&c); err != nil {
+	data, fver, err := migrateFile(file, configMigrations)
+	if err != nil {
+		return Config{}, err
+	}
+
+	c := Config{path: file.Name(), migratedFrom: fver}
+	if err := yaml.Unmarshal(data, &c); err != nil {
 		return Config{}, err
 	}
 
 	return c, nil
 }
 
+// Update the Config with the provided func and persist it.
+// File is locked for the time of the update and the latest persisted Config is passed into fn,
+// so the concurrent updates made by the other mnqnctl processes are not lost.
+// Nothing is persisted if fn returns an error.
+func (c *Config) Update(fn func(c *Config) error) error {
+	if c.path == "" {
+		return errors.New("configuration file is not set")
+	}
+
+	unlock, err := lockFile(c.path)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	f, err := os.Open(c.path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	latest, err := NewConfigFromFile(f)
+	if err != nil {
+		return fmt.Errorf("couldn't read latest configuration: %s", err)
+	}
+
+	if err := fn(&latest); err != nil {
+		return err
+	}
+	if err := latest.save(""); err != nil {
+		return err
+	}
+
+	*c = latest
+	return nil
+}
+
 // Register the Project.
 func (c *Config) Register(p Project) error {
 	if err := p.Validate(); err != nil {
 		return err
 	}
 
-	if c.Projects != nil {
+	return c.Update(func(c *Config) error {
 		for _, pp := range c.Projects {
 			if pp.Name == p.Name {
 				if pp.Path == p.Path {
 					return fmt.Errorf("project with name \"%s\" is already registered", p.Name)
 				}
 
-				return fmt.Errorf("project \"%s\" is already registered but on different path (%s): re-register the project or move it back to the original path", pp.Name, pp.Path)
+				return fmt.Errorf("project \"%s\" is already registered but on different path (%s): run \"mnqnctl projects move %s %s\" or move it back to the original path", pp.Name, pp.Path, pp.Name, p.Path)
+			}
+		}
+
+		c.Projects = append(c.Projects, p)
+		return nil
+	})
+}
+
+// Project returns registered Project by the provided name.
+func (c *Config) Project(name string) (Project, error) {
+	i, err := c.projectIdx(name)
+	if err != nil {
+		return Project{}, err
+	}
+
+	return c.Projects[i], nil
+}
+
+// SetProfile persists the default profile of the registered Project.
+// Empty profile resets the default.
+func (c *Config) SetProfile(name, profile string) error {
+	return c.Update(func(c *Config) error {
+		i, err := c.projectIdx(name)
+		if err != nil {
+			return err
+		}
+
+		c.Projects[i].Profile = profile
+		return nil
+	})
+}
+
+// SetContext persists the kubernetes context of the registered Project.
+// Empty context resets it.
+func (c *Config) SetContext(name, k8sCtx string) error {
+	return c.Update(func(c *Config) error {
+		i, err := c.projectIdx(name)
+		if err != nil {
+			return err
+		}
+
+		c.Projects[i].Context = k8sCtx
+		return nil
+	})
+}
+
+// Unregister the Project.
+func (c *Config) Unregister(name string) error {
+	return c.Update(func(c *Config) error {
+		i, err := c.projectIdx(name)
+		if err != nil {
+			return err
+		}
+
+		c.Projects = append(c.Projects[:i], c.Projects[i+1:]...)
+		return nil
+	})
+}
+
+// Move the registered Project to the new path.
+func (c *Config) Move(name, path string) error {
+	if path == "" {
+		return errors.New("path is required")
+	}
+
+	return c.Update(func(c *Config) error {
+		i, err := c.projectIdx(name)
+		if err != nil {
+			return err
+		}
+
+		c.Projects[i].Path = path
+		return nil
+	})
+}
+
+// Rename the registered Project.
+func (c *Config) Rename(name, newName string) error {
+	if newName == "" {
+		return errors.New("new name is required")
+	}
+
+	return c.Update(func(c *Config) error {
+		if _, err := c.projectIdx(newName); err == nil {
+			return fmt.Errorf("project with name \"%s\" is already registered", newName)
+		}
+
+		i, err := c.projectIdx(name)
+		if err != nil {
+			return err
+		}
+
+		c.Projects[i].Name = newName
+		return nil
+	})
+}
+
+// Prune unregisters the Projects whose path no longer contains the local configuration.
+// Returns the unregistered Projects.
+func (c *Config) Prune() ([]Project, error) {
+	var pruned []Project
+	err := c.Update(func(c *Config) error {
+		var kept []Project
+		for _, p := range c.Projects {
+			if !p.Exists() {
+				pruned = append(pruned, p)
+				continue
 			}
+
+			kept = append(kept, p)
+		}
+
+		c.Projects = kept
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return pruned, nil
+}
+
+// InstallID returns the ID of the installation.
+// The ID is generated and persisted on the first call.
+func (c *Config) InstallID() (string, error) {
+	if c.Install != "" {
+		return c.Install, nil
+	}
+
+	err := c.Update(func(c *Config) error {
+		if c.Install != "" {
+			return nil
+		}
+
+		raw := make([]byte, 8)
+		if _, err := rand.Read(raw); err != nil {
+			return fmt.Errorf("couldn't generate install ID: %s", err)
 		}
+		c.Install = hex.EncodeToString(raw)
+
+		return nil
+	})
+	if err != nil {
+		return "", err
 	}
 
-	c.Projects = append(c.Projects, p)
+	return c.Install, nil
+}
+
+func (c *Config) projectIdx(name string) (int, error) {
+	for i, p := range c.Projects {
+		if p.Name == name {
+			return i, nil
+		}
+	}
 
-	return c.Save(c.file)
+	return 0, fmt.Errorf("project \"%s\" is not registered", name)
 }
 
 // Validate the Config.
@@ -69,31 +272,43 @@ func (c *Config) Validate() error {
 	return nil
 }
 
-// Save the Config to the provided path as a .yaml file.
-// If file is not provided but Config was created from file -
+// save the Config to the provided path as a .yaml file.
+// If path is not provided but Config was created from file -
 // config would be written to the file.
-func (c *Config) Save(file *os.File) error {
-	if file == nil && c.file != nil {
-		file = c.file
+// File is replaced atomically, Update is the only way to modify the persisted Config.
+func (c *Config) save(path string) error {
+	if path != "" {
+		c.path = path
+	}
+	if c.path == "" {
+		return errors.New("configuration file is not set")
 	}
 
-	if err := file.Truncate(0); err != nil {
-		return err
+	if err := c.Validate(); err != nil {
+		return fmt.Errorf("configuration is not valid: %s", err)
 	}
-	if _, err := file.Seek(0, 0); err != nil {
+
+	data, err := yaml.Marshal(c)
+	if err != nil {
 		return err
 	}
-	if err := yaml.NewEncoder(file).Encode(c); err != nil {
-		return err
+
+	if c.migratedFrom != "" {
+		if err := backupMigrated(c.path, c.migratedFrom); err != nil {
+			return err
+		}
+		c.migratedFrom = ""
 	}
 
-	return file.Sync()
+	return writeFileAtomic(c.path, data, 0600)
 }
 
 // Project data that is registered for further use.
 type Project struct {
-	Name string `yaml:"name"`
-	Path string `yaml:"path"`
+	Name    string `yaml:"name"`
+	Path    string `yaml:"path"`
+	Profile string `yaml:"profile,omitempty"`
+	Context string `yaml:"context,omitempty"`
 }
 
 // NewProject is a constructor.
@@ -105,6 +320,17 @@ func NewProject(path string) (Project, error) {
 	return Project{Path: path}, nil
 }
 
+// LConfigPath returns the path of the local configuration of the Project.
+func (p *Project) LConfigPath() string {
+	return filepath.Join(p.Path, DefaultLConfigFileName)
+}
+
+// Exists returns true if the path of the Project contains the local configuration.
+func (p *Project) Exists() bool {
+	i, err := os.Stat(p.LConfigPath())
+	return err == nil && !i.IsDir()
+}
+
 // Validate the Project.
 func (p *Project) Validate() error {
 	switch {
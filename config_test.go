@@ -0,0 +1,131 @@
+package mannequin
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+)
+
+func TestConfigUpdateConcurrent(t *testing.T) {
+	dir, err := ioutil.TempDir("", "config")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, configFile)
+	c, err := NewConfig(ver)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := c.save(path); err != nil {
+		t.Fatal(err)
+	}
+
+	// every update is made by its own Config, as the separate mnqnctl processes do.
+	const updates = 20
+	var wg sync.WaitGroup
+	errs := make(chan error, updates)
+	for i := 0; i < updates; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+
+			f, err := os.Open(path)
+			if err != nil {
+				errs <- err
+				return
+			}
+			c, err := NewConfigFromFile(f)
+			f.Close()
+			if err != nil {
+				errs <- err
+				return
+			}
+
+			errs <- c.Update(func(c *Config) error {
+				c.AllowedContexts = append(c.AllowedContexts, fmt.Sprintf("ctx-%d", i))
+				return nil
+			})
+		}(i)
+	}
+	wg.Wait()
+	close(errs)
+
+	for err := range errs {
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	latest, err := NewConfigFromFile(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(latest.AllowedContexts) != updates {
+		t.Fatalf("expected %d contexts, got %v", updates, latest.AllowedContexts)
+	}
+	seen := map[string]bool{}
+	for _, ctx := range latest.AllowedContexts {
+		seen[ctx] = true
+	}
+	for i := 0; i < updates; i++ {
+		if !seen[fmt.Sprintf("ctx-%d", i)] {
+			t.Errorf("update of ctx-%d is lost", i)
+		}
+	}
+}
+
+func TestConfigUpdateError(t *testing.T) {
+	dir, err := ioutil.TempDir("", "config")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, configFile)
+	c, err := NewConfig(ver)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := c.save(path); err != nil {
+		t.Fatal(err)
+	}
+	before, err := ioutil.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = c.Update(func(c *Config) error {
+		c.Context = "changed"
+		return fmt.Errorf("failed")
+	})
+	if err == nil {
+		t.Fatal("expected the error of the update")
+	}
+
+	after, err := ioutil.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(before) != string(after) {
+		t.Errorf("configuration is persisted despite the error: %s", after)
+	}
+	if c.Context != "" {
+		t.Errorf("configuration is changed despite the error: %s", c.Context)
+	}
+
+	var unsaved Config
+	if err := unsaved.Update(func(c *Config) error { return nil }); err == nil {
+		t.Error("expected the configuration without the file to be rejected")
+	}
+}
@@ -0,0 +1,143 @@
+package mannequin
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/kostkobv/mannequin/pkg"
+	"github.com/kostkobv/mannequin/pkg/docker"
+	"github.com/kostkobv/mannequin/pkg/helm"
+	"github.com/kostkobv/mannequin/pkg/kustomize"
+	"github.com/kostkobv/mannequin/pkg/manifests"
+)
+
+// DeployType is the backend that deploys the project.
+type DeployType string
+
+// Available DeployTypes.
+const (
+	DeployHelm      DeployType = "helm"
+	DeployKustomize DeployType = "kustomize"
+	DeployManifests DeployType = "manifests"
+)
+
+var validDeployTypes = []DeployType{DeployHelm, DeployKustomize, DeployManifests}
+
+// DefaultDeployTimeout is used if the deployment is waited for but no timeout is set.
+const DefaultDeployTimeout = 5 * time.Minute
+
+// DeployConfig of the project.
+// Type is DeployHelm by default.
+// If Wait is set - deployment is done once every workload is ready.
+type DeployConfig struct {
+	Type    DeployType `yaml:"type,omitempty,flow"`
+	Wait    bool       `yaml:"wait,omitempty,flow"`
+	Timeout string     `yaml:"timeout,omitempty,flow"`
+}
+
+// Validate the DeployConfig.
+func (dc *DeployConfig) Validate() error {
+	var validType bool
+	for _, t := range validDeployTypes {
+		validType = validType || dc.Type == t
+	}
+	if dc.Type != "" && !validType {
+		return fmt.Errorf("%s is not a valid deploy type (expected one of: %s)", dc.Type, joinDeployTypes())
+	}
+
+	if dc.Timeout != "" {
+		if _, err := time.ParseDuration(dc.Timeout); err != nil {
+			return fmt.Errorf("timeout \"%s\" is not a valid duration (e.g. 5m)", dc.Timeout)
+		}
+	}
+
+	return nil
+}
+
+// DeployType of the project.
+func (lc *LConfig) DeployType() DeployType {
+	if lc.Deploy.Type == "" {
+		return DeployHelm
+	}
+
+	return lc.Deploy.Type
+}
+
+// Deployer of the project for the provided kubernetes context.
+// Namespace overrides the one of the configuration if provided.
+// Install is the ID of the installation the applied manifests are labeled with (see Config.InstallID).
+func (lc *LConfig) Deployer(k8sCtx, namespace, install string) (pkg.Deployer, error) {
+	if err := lc.Deploy.Validate(); err != nil {
+		return nil, err
+	}
+
+	t := pkg.Target{KubeContext: k8sCtx, Wait: lc.Deploy.Wait}
+	if t.Wait {
+		t.Timeout = DefaultDeployTimeout
+		if lc.Deploy.Timeout != "" {
+			t.Timeout, _ = time.ParseDuration(lc.Deploy.Timeout)
+		}
+	}
+
+	switch lc.DeployType() {
+	case DeployKustomize:
+		kc := lc.Kustomize
+		if namespace != "" {
+			kc.Namespace = namespace
+		}
+		if kc.Image == "" {
+			kc.Image = lc.Name
+		}
+
+		return kustomize.NewDeployer(kc, t, "$"+string(docker.VarDockerImageName), "$"+string(docker.VarDockerImageVersion)), nil
+	case DeployManifests:
+		mc := lc.Manifests
+		if namespace != "" {
+			mc.Namespace = namespace
+		}
+
+		return manifests.NewDeployer(mc, t, lc.Name, install), nil
+	default:
+		hc := lc.Helm
+		if namespace != "" {
+			hc.Namespace = namespace
+		}
+
+		return helm.NewDeployer(hc, t), nil
+	}
+}
+
+// Release returns the name and the namespace the project is deployed with.
+func (lc *LConfig) Release() (string, string) {
+	switch lc.DeployType() {
+	case DeployKustomize:
+		return lc.Name, lc.Kustomize.Namespace
+	case DeployManifests:
+		return lc.Name, lc.Manifests.Namespace
+	}
+
+	// helm deploys into the namespace named after the release by default.
+	ns := lc.Helm.Namespace
+	if ns == "" {
+		ns = lc.Helm.ReleaseName
+	}
+
+	return lc.Helm.ReleaseName, ns
+}
+
+// JSONSchema impl.
+func (t DeployType) JSONSchema() map[string]interface{} {
+	return map[string]interface{}{"type": "string", "enum": validDeployTypes}
+}
+
+func joinDeployTypes() string {
+	var res string
+	for i, t := range validDeployTypes {
+		if i != 0 {
+			res += ", "
+		}
+		res += string(t)
+	}
+
+	return res
+}
@@ -0,0 +1,55 @@
+package feat
+
+import (
+	"sort"
+)
+
+// Kinds of the values the arguments are completed with.
+// Dynamic kinds are resolved by mnqnctl at the time of the completion.
+const (
+	ArgFiles    = "files"
+	ArgNone     = "none"
+	ArgProjects = "projects"
+	ArgContexts = "contexts"
+	ArgProfiles = "profiles"
+	ArgBackups  = "backups"
+)
+
+// Arg describes the values the positional argument is completed with.
+type Arg struct {
+	// Kind of the values, ArgFiles by default.
+	Kind string
+	// Values that are offered in addition to the ones of the Kind.
+	Values []string
+}
+
+// ArgsCompleter is implemented by the FeatDoers whose positional arguments could be completed.
+type ArgsCompleter interface {
+	// CompleteArgs returns the completion of the arguments in order,
+	// the last one is used for the rest of the arguments.
+	CompleteArgs() []Arg
+}
+
+// Hider is implemented by the FeatDoers that are not listed in the info and the completion.
+type Hider interface {
+	Hidden() bool
+}
+
+// Names of the registered visible features in alphabetical order.
+func (f *Feats) Names() []string {
+	var res []string
+	for n, fd := range f.fs {
+		if hidden(fd) {
+			continue
+		}
+		res = append(res, n)
+	}
+	sort.Strings(res)
+
+	return res
+}
+
+func hidden(fd FeatDoer) bool {
+	h, ok := fd.(Hider)
+	return ok && h.Hidden()
+}
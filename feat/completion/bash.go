@@ -0,0 +1,123 @@
+package completion
+
+import (
+	"fmt"
+	"io"
+	"strings"
+)
+
+// bash completion script.
+func bash(w io.Writer, root command) error {
+	cmds := root.all()
+	names, kinds := root.valueFlags()
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "# bash completion for %s, generated by `%s completion bash`.\n", bin, bin)
+	fmt.Fprintf(&b, "# Load it with `source <(%s completion bash)`.\n\n", bin)
+
+	fmt.Fprintf(&b, "__%s_values() {\n", bin)
+	fmt.Fprintln(&b, `    case "$1" in`)
+	fmt.Fprintln(&b, `    files) compgen -f -- "$2" ;;`)
+	fmt.Fprintln(&b, `    none) ;;`)
+	fmt.Fprintf(&b, "    *) compgen -W \"$(%s ${cfg:+--%s=\"$cfg\"} %s \"$1\" 2>/dev/null)\" -- \"$2\" ;;\n", bin, configFlag, CompleteName)
+	fmt.Fprintln(&b, "    esac")
+	fmt.Fprintln(&b, "}")
+	fmt.Fprintln(&b)
+
+	fmt.Fprintf(&b, "_%s() {\n", bin)
+	fmt.Fprintln(&b, `    local cur="${COMP_WORDS[COMP_CWORD]}" prev="${COMP_WORDS[COMP_CWORD-1]}"`)
+	fmt.Fprintf(&b, "    local cmd=%s pos=0 skip=\"\" cfg=\"\" i w name\n", bin)
+	fmt.Fprintln(&b, "    COMPREPLY=()")
+	fmt.Fprintln(&b)
+	fmt.Fprintln(&b, "    # find the command and the position of the current argument.")
+	fmt.Fprintln(&b, "    for ((i = 1; i < COMP_CWORD; i++)); do")
+	fmt.Fprintln(&b, `        w="${COMP_WORDS[i]}"`)
+	fmt.Fprintln(&b, `        if [[ -n "$skip" ]]; then`)
+	fmt.Fprintf(&b, "            [[ \"$skip\" == %s ]] && cfg=\"$w\"\n", configFlag)
+	fmt.Fprintln(&b, `            skip=""`)
+	fmt.Fprintln(&b, "            continue")
+	fmt.Fprintln(&b, "        fi")
+	fmt.Fprintln(&b, `        case "$w" in`)
+	fmt.Fprintln(&b, "        -*=*)")
+	fmt.Fprintln(&b, `            name="${w%%=*}"`)
+	fmt.Fprintln(&b, `            name="${name#-}"`)
+	fmt.Fprintf(&b, "            [[ \"${name#-}\" == %s ]] && cfg=\"${w#*=}\"\n", configFlag)
+	fmt.Fprintln(&b, "            continue")
+	fmt.Fprintln(&b, "            ;;")
+	fmt.Fprintln(&b, "        -*)")
+	fmt.Fprintln(&b, `            name="${w#-}"`)
+	fmt.Fprintln(&b, `            name="${name#-}"`)
+	fmt.Fprintln(&b, `            case "$name" in`)
+	fmt.Fprintf(&b, "            %s) skip=\"$name\" ;;\n", strings.Join(names, "|"))
+	fmt.Fprintln(&b, "            esac")
+	fmt.Fprintln(&b, "            continue")
+	fmt.Fprintln(&b, "            ;;")
+	fmt.Fprintln(&b, "        esac")
+	fmt.Fprintln(&b, `        case "$pos:$cmd $w" in`)
+	var paths []string
+	for _, c := range cmds[1:] {
+		paths = append(paths, fmt.Sprintf(`"0:%s"`, c.path))
+	}
+	fmt.Fprintf(&b, "        %s) cmd=\"$cmd $w\" ;;\n", strings.Join(paths, "|"))
+	fmt.Fprintln(&b, "        *) pos=$((pos + 1)) ;;")
+	fmt.Fprintln(&b, "        esac")
+	fmt.Fprintln(&b, "    done")
+	fmt.Fprintln(&b, `    cfg="${cfg/#\~/$HOME}"`)
+	fmt.Fprintln(&b)
+
+	fmt.Fprintln(&b, "    # values of the flags.")
+	fmt.Fprintln(&b, `    case "$prev" in`)
+	fmt.Fprintln(&b, "    -*=*) ;;")
+	fmt.Fprintln(&b, "    -*)")
+	fmt.Fprintln(&b, `        name="${prev#-}"`)
+	fmt.Fprintln(&b, `        case "${name#-}" in`)
+	for _, n := range names {
+		fmt.Fprintf(&b, "        %s)\n", n)
+		fmt.Fprintf(&b, "            COMPREPLY=($(__%s_values %s \"$cur\"))\n", bin, kinds[n])
+		fmt.Fprintln(&b, "            return")
+		fmt.Fprintln(&b, "            ;;")
+	}
+	fmt.Fprintln(&b, "        esac")
+	fmt.Fprintln(&b, "        ;;")
+	fmt.Fprintln(&b, "    esac")
+	fmt.Fprintln(&b)
+
+	fmt.Fprintln(&b, `    case "$cur" in`)
+	fmt.Fprintln(&b, "    -*)")
+	fmt.Fprintln(&b, `        case "$cmd" in`)
+	for _, c := range cmds {
+		var fls []string
+		for _, f := range c.flags {
+			fls = append(fls, "--"+f.name)
+		}
+		fmt.Fprintf(&b, "        \"%s\") COMPREPLY=($(compgen -W \"%s\" -- \"$cur\")) ;;\n", c.path, strings.Join(fls, " "))
+	}
+	fmt.Fprintln(&b, "        esac")
+	fmt.Fprintln(&b, "        return")
+	fmt.Fprintln(&b, "        ;;")
+	fmt.Fprintln(&b, "    esac")
+	fmt.Fprintln(&b)
+
+	fmt.Fprintln(&b, `    local kind=files values=""`)
+	fmt.Fprintln(&b, `    case "$pos:$cmd" in`)
+	for _, c := range cmds {
+		if len(c.subs) != 0 {
+			fmt.Fprintf(&b, "    \"0:%s\") kind=none values=\"%s\" ;;\n", c.path, strings.Join(c.subNames(), " "))
+		}
+		for i, a := range c.args {
+			pos := fmt.Sprintf("\"%d:%s\"", i, c.path)
+			if i == len(c.args)-1 {
+				pos = fmt.Sprintf("*\":%s\"", c.path)
+			}
+			fmt.Fprintf(&b, "    %s) kind=%s values=\"%s\" ;;\n", pos, argKind(a), strings.Join(a.Values, " "))
+		}
+	}
+	fmt.Fprintln(&b, "    esac")
+	fmt.Fprintf(&b, "    COMPREPLY=($(compgen -W \"$values\" -- \"$cur\") $(__%s_values \"$kind\" \"$cur\"))\n", bin)
+	fmt.Fprintln(&b, "}")
+	fmt.Fprintln(&b)
+	fmt.Fprintf(&b, "complete -o filenames -F _%s %s\n", bin, bin)
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
@@ -0,0 +1,113 @@
+package completion
+
+import (
+	"fmt"
+	"io"
+	"io/ioutil"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/kostkobv/mannequin"
+	"github.com/kostkobv/mannequin/feat"
+	"github.com/kostkobv/mannequin/pkg/kubectl"
+
+	"gopkg.in/yaml.v2"
+)
+
+// CompleteName is the name of the hidden feature the scripts get the dynamic values from.
+const CompleteName = "__complete"
+
+// Complete feature prints the values of the dynamic kind one per line.
+type Complete struct{}
+
+// NewComplete is a constructor for Complete.
+func NewComplete() *Complete {
+	return &Complete{}
+}
+
+// Name impl.
+func (cm *Complete) Name() string {
+	return CompleteName
+}
+
+// Do impl.
+// Nothing is printed if the values couldn't be resolved, so the errors don't end up in the completion.
+func (cm *Complete) Do(c mannequin.Mnqn, args ...string) error {
+	if len(args) != 1 {
+		return nil
+	}
+
+	vals, err := values(c, args[0])
+	if err != nil {
+		c.Debugf("Couldn't complete %s: %s\n", args[0], err)
+		return nil
+	}
+
+	for _, v := range vals {
+		fmt.Fprintln(c, v)
+	}
+
+	return nil
+}
+
+// Hidden impl.
+func (cm *Complete) Hidden() bool {
+	return true
+}
+
+// Info impl.
+func (cm *Complete) Info() io.Reader {
+	return strings.NewReader("Prints the values for the shell completion")
+}
+
+func values(c mannequin.Mnqn, kind string) ([]string, error) {
+	switch kind {
+	case feat.ArgProjects:
+		var res []string
+		for _, p := range c.Config.Projects {
+			res = append(res, p.Name)
+		}
+		return res, nil
+	case feat.ArgContexts:
+		return kubectl.Contexts()
+	case feat.ArgProfiles:
+		return profiles()
+	case feat.ArgBackups:
+		bs, err := mannequin.Backups()
+		if err != nil {
+			return nil, err
+		}
+		res := make([]string, 0, len(bs))
+		for _, b := range bs {
+			res = append(res, strings.TrimSuffix(filepath.Base(b), ".tar.gz"))
+		}
+		return res, nil
+	}
+
+	return nil, fmt.Errorf("unknown kind \"%s\"", kind)
+}
+
+// profiles of the local configuration in the working directory.
+// Only the names are read, so the file is neither migrated nor validated.
+func profiles() ([]string, error) {
+	data, err := ioutil.ReadFile(mannequin.DefaultLConfigFileName)
+	if err != nil {
+		return nil, err
+	}
+
+	var lc struct {
+		Profiles map[string]interface{} `yaml:"profiles"`
+	}
+	if err := yaml.Unmarshal(data, &lc); err != nil {
+		return nil, err
+	}
+
+	res := make([]string, 0, len(lc.Profiles))
+	for n := range lc.Profiles {
+		res = append(res, n)
+	}
+	sort.Strings(res)
+
+	return res, nil
+}
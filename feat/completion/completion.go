@@ -0,0 +1,184 @@
+package completion
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"strings"
+
+	"github.com/kostkobv/mannequin"
+	"github.com/kostkobv/mannequin/feat"
+)
+
+// Name of the completion feature.
+const Name = "completion"
+
+// bin is the name of the binary the scripts complete.
+const bin = "mnqnctl"
+
+// configFlag is passed through to the dynamic completion,
+// so the values are resolved from the same global configuration.
+const configFlag = "config"
+
+// scripts generators by the shell.
+var scripts = map[string]func(w io.Writer, root command) error{
+	"bash": bash,
+	"zsh":  zsh,
+	"fish": fish,
+}
+
+// Completion feature.
+type Completion struct {
+	root *feat.Feats
+}
+
+// New is a constructor for Completion.
+// Scripts are generated from the features of the root.
+func New(root *feat.Feats) *Completion {
+	return &Completion{root: root}
+}
+
+// Name impl.
+func (cm *Completion) Name() string {
+	return Name
+}
+
+// Do impl.
+func (cm *Completion) Do(c mannequin.Mnqn, args ...string) error {
+	if len(args) != 1 {
+		return errors.New("usage: completion bash|zsh|fish")
+	}
+
+	gen, ok := scripts[args[0]]
+	if !ok {
+		return fmt.Errorf("shell \"%s\" is not supported: use bash, zsh or fish", args[0])
+	}
+
+	return gen(c, tree(bin, cm.root))
+}
+
+// CompleteArgs impl.
+func (cm *Completion) CompleteArgs() []feat.Arg {
+	return []feat.Arg{{Kind: feat.ArgNone, Values: []string{"bash", "zsh", "fish"}}}
+}
+
+// Info impl.
+func (cm *Completion) Info() io.Reader {
+	return strings.NewReader("Prints the shell completion script (bash, zsh or fish), " +
+		"e.g. `source <(mnqnctl completion bash)`")
+}
+
+// command of the features tree as it's completed.
+type command struct {
+	path  string
+	info  string
+	subs  []command
+	flags []flagSpec
+	args  []feat.Arg
+}
+
+type flagSpec struct {
+	name  string
+	usage string
+	// kind of the value, empty for the bool flags.
+	kind string
+}
+
+// tree of the commands of the FeatDoer and it's sub features.
+func tree(path string, fd feat.FeatDoer) command {
+	cmd := command{path: path, info: firstLine(fd.Info())}
+
+	feat.FlagSet(&mannequin.Mnqn{}, fd).VisitAll(func(f *flag.Flag) {
+		fl := flagSpec{name: f.Name, usage: f.Usage}
+		if b, ok := f.Value.(interface{ IsBoolFlag() bool }); !ok || !b.IsBoolFlag() {
+			fl.kind = flagKind(f.Name)
+		}
+		cmd.flags = append(cmd.flags, fl)
+	})
+
+	if ac, ok := fd.(feat.ArgsCompleter); ok {
+		cmd.args = ac.CompleteArgs()
+	}
+
+	if p, ok := fd.(feat.Parent); ok {
+		subs := p.Subs()
+		for _, n := range subs.Names() {
+			sfd, err := subs.ByName(n)
+			if err != nil {
+				continue
+			}
+			cmd.subs = append(cmd.subs, tree(path+" "+n, sfd))
+		}
+	}
+
+	return cmd
+}
+
+// all the commands of the tree including the root.
+func (cmd command) all() []command {
+	res := []command{cmd}
+	for _, s := range cmd.subs {
+		res = append(res, s.all()...)
+	}
+
+	return res
+}
+
+// subNames of the command.
+func (cmd command) subNames() []string {
+	res := make([]string, 0, len(cmd.subs))
+	for _, s := range cmd.subs {
+		res = append(res, s.path[strings.LastIndex(s.path, " ")+1:])
+	}
+
+	return res
+}
+
+// valueFlags of every command of the tree by the name.
+func (cmd command) valueFlags() ([]string, map[string]string) {
+	var names []string
+	kinds := map[string]string{}
+	for _, c := range cmd.all() {
+		for _, f := range c.flags {
+			if _, ok := kinds[f.name]; ok || f.kind == "" {
+				continue
+			}
+			names = append(names, f.name)
+			kinds[f.name] = f.kind
+		}
+	}
+
+	return names, kinds
+}
+
+// flagKind returns the kind of the value of the flag by it's name.
+func flagKind(name string) string {
+	switch name {
+	case "context":
+		return feat.ArgContexts
+	case "profile":
+		return feat.ArgProfiles
+	case "namespace":
+		return feat.ArgNone
+	}
+
+	return feat.ArgFiles
+}
+
+func argKind(a feat.Arg) string {
+	if a.Kind == "" {
+		return feat.ArgFiles
+	}
+
+	return a.Kind
+}
+
+func firstLine(r io.Reader) string {
+	// info is read completely, so the writer of the pipe is not blocked.
+	data, _ := ioutil.ReadAll(r)
+	line := strings.TrimSpace(strings.SplitN(strings.TrimSpace(string(data)), "\n", 2)[0])
+
+	return strings.TrimSuffix(line, ".")
+}
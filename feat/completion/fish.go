@@ -0,0 +1,146 @@
+package completion
+
+import (
+	"fmt"
+	"io"
+	"strings"
+)
+
+// fish completion script.
+func fish(w io.Writer, root command) error {
+	cmds := root.all()
+	names, _ := root.valueFlags()
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "# fish completion for %s, generated by `%s completion fish`.\n", bin, bin)
+	fmt.Fprintf(&b, "# Load it with `%s completion fish | source`.\n\n", bin)
+
+	// command and the position of the current argument are resolved the same way as in bash.
+	fmt.Fprintf(&b, "function __%s_cmd\n", bin)
+	fmt.Fprintf(&b, "    set -l cmd %s\n", bin)
+	fmt.Fprintln(&b, "    set -l pos 0")
+	fmt.Fprintln(&b, "    set -l skip 0")
+	fmt.Fprintln(&b, "    for w in (commandline -opc)[2..-1]")
+	fmt.Fprintln(&b, "        if test $skip = 1")
+	fmt.Fprintln(&b, "            set skip 0")
+	fmt.Fprintln(&b, "            continue")
+	fmt.Fprintln(&b, "        end")
+	fmt.Fprintln(&b, "        switch $w")
+	fmt.Fprintln(&b, "            case '-*=*'")
+	fmt.Fprintln(&b, "                continue")
+	fmt.Fprintf(&b, "            case %s\n", fishFlags(names))
+	fmt.Fprintln(&b, "                set skip 1")
+	fmt.Fprintln(&b, "                continue")
+	fmt.Fprintln(&b, "            case '-*'")
+	fmt.Fprintln(&b, "                continue")
+	fmt.Fprintln(&b, "        end")
+	fmt.Fprintln(&b, `        switch "$pos:$cmd $w"`)
+	var paths []string
+	for _, c := range cmds[1:] {
+		paths = append(paths, fishQuote("0:"+c.path))
+	}
+	fmt.Fprintf(&b, "            case %s\n", strings.Join(paths, " "))
+	fmt.Fprintln(&b, `                set cmd "$cmd $w"`)
+	fmt.Fprintln(&b, "            case '*'")
+	fmt.Fprintln(&b, "                set pos (math $pos + 1)")
+	fmt.Fprintln(&b, "        end")
+	fmt.Fprintln(&b, "    end")
+	fmt.Fprintln(&b, `    echo "$pos:$cmd"`)
+	fmt.Fprintln(&b, "end")
+	fmt.Fprintln(&b)
+
+	// global configuration is passed through to the dynamic completion.
+	fmt.Fprintf(&b, "function __%s_config\n", bin)
+	fmt.Fprintln(&b, "    set -l next 0")
+	fmt.Fprintln(&b, "    for w in (commandline -opc)[2..-1]")
+	fmt.Fprintln(&b, "        if test $next = 1")
+	fmt.Fprintf(&b, "            printf '%%s\\n' \"--%s=$w\"\n", configFlag)
+	fmt.Fprintln(&b, "            return")
+	fmt.Fprintln(&b, "        end")
+	fmt.Fprintln(&b, "        switch $w")
+	fmt.Fprintf(&b, "            case %s %s\n", fishQuote("-"+configFlag+"=*"), fishQuote("--"+configFlag+"=*"))
+	fmt.Fprintf(&b, "                printf '%%s\\n' \"--%s=\"(string split -m1 = -- $w)[2]\n", configFlag)
+	fmt.Fprintln(&b, "                return")
+	fmt.Fprintf(&b, "            case %s %s\n", fishQuote("-"+configFlag), fishQuote("--"+configFlag))
+	fmt.Fprintln(&b, "                set next 1")
+	fmt.Fprintln(&b, "        end")
+	fmt.Fprintln(&b, "    end")
+	fmt.Fprintln(&b, "end")
+	fmt.Fprintln(&b)
+
+	// condition is true if the current argument is of the command and it's position is within the range.
+	fmt.Fprintf(&b, "function __%s_is\n", bin)
+	fmt.Fprintf(&b, "    set -l cur (string split -m1 : -- (__%s_cmd))\n", bin)
+	fmt.Fprintln(&b, `    test "$cur[2]" = "$argv[1]"; or return 1`)
+	fmt.Fprintln(&b, `    test -z "$argv[2]"; or test $cur[1] -ge $argv[2]; or return 1`)
+	fmt.Fprintln(&b, `    test -z "$argv[3]"; or test $cur[1] -le $argv[3]`)
+	fmt.Fprintln(&b, "end")
+	fmt.Fprintln(&b)
+
+	fmt.Fprintf(&b, "complete -c %s -f\n", bin)
+	for _, c := range cmds {
+		cond := fmt.Sprintf("__%s_is %s", bin, fishQuote(c.path))
+		for _, s := range c.subs {
+			fmt.Fprintf(&b, "complete -c %s -n %s -a %s -d %s\n", bin,
+				fishQuote(cond+" 0 0"), s.path[strings.LastIndex(s.path, " ")+1:], fishQuote(s.info))
+		}
+
+		for _, f := range c.flags {
+			fmt.Fprintf(&b, "complete -c %s -n %s -l %s -d %s%s\n", bin,
+				fishQuote(cond), f.name, fishQuote(f.usage), fishValues(f.kind, nil, true))
+		}
+
+		for i, a := range c.args {
+			pos := fmt.Sprintf(" %d %d", i, i)
+			if i == len(c.args)-1 {
+				pos = fmt.Sprintf(" %d", i)
+			}
+			vals := fishValues(argKind(a), a.Values, false)
+			if vals == "" {
+				continue
+			}
+			fmt.Fprintf(&b, "complete -c %s -n %s%s\n", bin, fishQuote(cond+pos), vals)
+		}
+	}
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// fishValues returns the options of the complete command for the values of the kind.
+func fishValues(kind string, vals []string, flag bool) string {
+	var opts string
+	if flag && kind != "" {
+		opts = " -r"
+	}
+
+	switch kind {
+	case "":
+		return opts
+	case "files":
+		opts += " -F"
+	case "none":
+	default:
+		vals = append(vals, fmt.Sprintf("(%s (__%s_config) %s %s 2>/dev/null)", bin, bin, CompleteName, kind))
+	}
+	if len(vals) != 0 {
+		opts += " -a " + fishQuote(strings.Join(vals, " "))
+	}
+
+	return opts
+}
+
+func fishFlags(names []string) string {
+	var res []string
+	for _, n := range names {
+		res = append(res, fishQuote("-"+n), fishQuote("--"+n))
+	}
+
+	return strings.Join(res, " ")
+}
+
+// fishQuote the value for the single quotes.
+func fishQuote(s string) string {
+	s = strings.Replace(s, `\`, `\\`, -1)
+	return "'" + strings.Replace(s, "'", `\'`, -1) + "'"
+}
@@ -0,0 +1,145 @@
+package completion
+
+import (
+	"fmt"
+	"io"
+	"strings"
+)
+
+// zsh completion script.
+func zsh(w io.Writer, root command) error {
+	cmds := root.all()
+	names, kinds := root.valueFlags()
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "#compdef %s\n", bin)
+	fmt.Fprintf(&b, "# zsh completion for %s, generated by `%s completion zsh`.\n", bin, bin)
+	fmt.Fprintf(&b, "# Load it with `source <(%s completion zsh)` after compinit.\n\n", bin)
+
+	fmt.Fprintf(&b, "__%s_values() {\n", bin)
+	fmt.Fprintln(&b, `    case "$1" in`)
+	fmt.Fprintln(&b, "    files) _files ;;")
+	fmt.Fprintln(&b, "    none) ;;")
+	fmt.Fprintf(&b, "    *) compadd -- ${(f)\"$(%s ${cfg:+--%s=$cfg} %s \"$1\" 2>/dev/null)\"} ;;\n", bin, configFlag, CompleteName)
+	fmt.Fprintln(&b, "    esac")
+	fmt.Fprintln(&b, "}")
+	fmt.Fprintln(&b)
+
+	fmt.Fprintf(&b, "_%s() {\n", bin)
+	fmt.Fprintln(&b, `    local cur="${words[CURRENT]}" prev="${words[CURRENT-1]}"`)
+	fmt.Fprintf(&b, "    local cmd=%s pos=0 skip=\"\" cfg=\"\" i w name\n", bin)
+	fmt.Fprintln(&b, "    local -a subs flags")
+	fmt.Fprintln(&b)
+	fmt.Fprintln(&b, "    # find the command and the position of the current argument.")
+	fmt.Fprintln(&b, "    for ((i = 2; i < CURRENT; i++)); do")
+	fmt.Fprintln(&b, `        w="${words[i]}"`)
+	fmt.Fprintln(&b, `        if [[ -n "$skip" ]]; then`)
+	fmt.Fprintf(&b, "            [[ \"$skip\" == %s ]] && cfg=\"$w\"\n", configFlag)
+	fmt.Fprintln(&b, `            skip=""`)
+	fmt.Fprintln(&b, "            continue")
+	fmt.Fprintln(&b, "        fi")
+	fmt.Fprintln(&b, `        case "$w" in`)
+	fmt.Fprintln(&b, "        -*=*)")
+	fmt.Fprintln(&b, `            name="${w%%=*}"`)
+	fmt.Fprintln(&b, `            name="${name#-}"`)
+	fmt.Fprintf(&b, "            [[ \"${name#-}\" == %s ]] && cfg=\"${w#*=}\"\n", configFlag)
+	fmt.Fprintln(&b, "            continue")
+	fmt.Fprintln(&b, "            ;;")
+	fmt.Fprintln(&b, "        -*)")
+	fmt.Fprintln(&b, `            name="${w#-}"`)
+	fmt.Fprintln(&b, `            name="${name#-}"`)
+	fmt.Fprintln(&b, `            case "$name" in`)
+	fmt.Fprintf(&b, "            %s) skip=\"$name\" ;;\n", strings.Join(names, "|"))
+	fmt.Fprintln(&b, "            esac")
+	fmt.Fprintln(&b, "            continue")
+	fmt.Fprintln(&b, "            ;;")
+	fmt.Fprintln(&b, "        esac")
+	fmt.Fprintln(&b, `        case "$pos:$cmd $w" in`)
+	var paths []string
+	for _, c := range cmds[1:] {
+		paths = append(paths, fmt.Sprintf(`"0:%s"`, c.path))
+	}
+	fmt.Fprintf(&b, "        %s) cmd=\"$cmd $w\" ;;\n", strings.Join(paths, "|"))
+	fmt.Fprintln(&b, "        *) pos=$((pos + 1)) ;;")
+	fmt.Fprintln(&b, "        esac")
+	fmt.Fprintln(&b, "    done")
+	fmt.Fprintln(&b, `    cfg="${cfg/#\~/$HOME}"`)
+	fmt.Fprintln(&b)
+
+	fmt.Fprintln(&b, "    # values of the flags.")
+	fmt.Fprintln(&b, `    case "$prev" in`)
+	fmt.Fprintln(&b, "    -*=*) ;;")
+	fmt.Fprintln(&b, "    -*)")
+	fmt.Fprintln(&b, `        name="${prev#-}"`)
+	fmt.Fprintln(&b, `        case "${name#-}" in`)
+	for _, n := range names {
+		fmt.Fprintf(&b, "        %s)\n", n)
+		fmt.Fprintf(&b, "            __%s_values %s\n", bin, kinds[n])
+		fmt.Fprintln(&b, "            return")
+		fmt.Fprintln(&b, "            ;;")
+	}
+	fmt.Fprintln(&b, "        esac")
+	fmt.Fprintln(&b, "        ;;")
+	fmt.Fprintln(&b, "    esac")
+	fmt.Fprintln(&b)
+
+	fmt.Fprintln(&b, `    case "$cmd" in`)
+	for _, c := range cmds {
+		var fls []string
+		for _, f := range c.flags {
+			fls = append(fls, zshQuote("--"+f.name+":"+f.usage))
+		}
+		fmt.Fprintf(&b, "    \"%s\")\n", c.path)
+		fmt.Fprintf(&b, "        flags=(%s)\n", strings.Join(fls, " "))
+		if len(c.subs) != 0 {
+			var subs []string
+			for _, s := range c.subs {
+				subs = append(subs, zshQuote(s.path[strings.LastIndex(s.path, " ")+1:]+":"+s.info))
+			}
+			fmt.Fprintf(&b, "        subs=(%s)\n", strings.Join(subs, " "))
+		}
+		fmt.Fprintln(&b, "        ;;")
+	}
+	fmt.Fprintln(&b, "    esac")
+	fmt.Fprintln(&b)
+
+	fmt.Fprintln(&b, `    if [[ "$cur" == -* ]]; then`)
+	fmt.Fprintln(&b, "        _describe flag flags")
+	fmt.Fprintln(&b, "        return")
+	fmt.Fprintln(&b, "    fi")
+	fmt.Fprintln(&b, "    if ((pos == 0 && ${#subs})); then")
+	fmt.Fprintln(&b, "        _describe command subs")
+	fmt.Fprintln(&b, "        return")
+	fmt.Fprintln(&b, "    fi")
+	fmt.Fprintln(&b)
+
+	fmt.Fprintln(&b, `    local kind=files values=""`)
+	fmt.Fprintln(&b, `    case "$pos:$cmd" in`)
+	for _, c := range cmds {
+		for i, a := range c.args {
+			pos := fmt.Sprintf("\"%d:%s\"", i, c.path)
+			if i == len(c.args)-1 {
+				pos = fmt.Sprintf("*\":%s\"", c.path)
+			}
+			fmt.Fprintf(&b, "    %s) kind=%s values=\"%s\" ;;\n", pos, argKind(a), strings.Join(a.Values, " "))
+		}
+	}
+	fmt.Fprintln(&b, "    esac")
+	fmt.Fprintln(&b, `    [[ -n "$values" ]] && compadd -- ${=values}`)
+	fmt.Fprintf(&b, "    __%s_values \"$kind\"\n", bin)
+	fmt.Fprintln(&b, "}")
+	fmt.Fprintln(&b)
+	fmt.Fprintf(&b, "compdef _%s %s\n", bin, bin)
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// zshQuote the value for the single quotes, colons are escaped for _describe.
+func zshQuote(s string) string {
+	i := strings.Index(s, ":")
+	name, desc := s[:i], s[i+1:]
+	desc = strings.Replace(desc, ":", `\:`, -1)
+
+	return "'" + strings.Replace(name+":"+desc, "'", `'\''`, -1) + "'"
+}
@@ -0,0 +1,63 @@
+package config
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/kostkobv/mannequin"
+	"github.com/kostkobv/mannequin/feat"
+)
+
+// Config feature.
+type Config struct {
+	SubFeats *feat.Feats
+}
+
+// New is a constructor for Config.
+func New(fds ...feat.FeatDoer) (*Config, error) {
+	f, err := feat.NewFeats("config", info, fds...)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Config{SubFeats: f}, nil
+}
+
+// Name impl.
+func (cf *Config) Name() string {
+	return "config"
+}
+
+// Do impl.
+func (cf *Config) Do(c mannequin.Mnqn, args ...string) error {
+	return cf.SubFeats.Do(c, args...)
+}
+
+// Subs impl.
+func (cf *Config) Subs() *feat.Feats {
+	return cf.SubFeats
+}
+
+// Info impl.
+func (cf *Config) Info() io.Reader {
+	return strings.NewReader("Validates the local configuration, publishes it's schema and shows where the global one is stored")
+}
+
+func info(f *feat.Feats) io.Reader {
+	r, w := io.Pipe()
+	go func(f *feat.Feats, w io.WriteCloser) {
+		fmt.Fprintln(w, "validate the local configuration, publish it's schema and show where the global one is stored")
+		fmt.Fprintln(w)
+		fmt.Fprintln(w, "For more information - https://github.com/kostkobv/mannequin")
+		fmt.Fprintln(w)
+		fmt.Fprintln(w, "Available commands:")
+		fmt.Fprintln(w)
+
+		f.FeatsInfo(w)
+
+		w.Close()
+	}(f, w)
+
+	return r
+}
@@ -0,0 +1,52 @@
+package paths
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/kostkobv/mannequin"
+)
+
+// Paths of the configuration feature.
+type Paths struct{}
+
+// New is a constructor for Paths.
+func New() *Paths {
+	return &Paths{}
+}
+
+// Name impl.
+func (p *Paths) Name() string {
+	return "paths"
+}
+
+// Do impl.
+func (p *Paths) Do(c mannequin.Mnqn, args ...string) error {
+	cfgPath := c.ConfigPath
+	if cfgPath == "" {
+		var err error
+		if cfgPath, err = mannequin.ConfigPath(); err != nil {
+			return err
+		}
+	}
+	keyPath, err := mannequin.SecretsKeyPath()
+	if err != nil {
+		return err
+	}
+	backupsPath, err := mannequin.BackupsFolderPath()
+	if err != nil {
+		return err
+	}
+
+	fmt.Fprintf(c, "config:  %s\n", cfgPath)
+	fmt.Fprintf(c, "secrets: %s\n", keyPath)
+	fmt.Fprintf(c, "backups: %s\n", backupsPath)
+
+	return nil
+}
+
+// Info impl.
+func (p *Paths) Info() io.Reader {
+	return strings.NewReader("Prints where the global configuration, secrets key and backups are stored")
+}
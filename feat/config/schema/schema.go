@@ -0,0 +1,56 @@
+package schema
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"strings"
+
+	"github.com/kostkobv/mannequin"
+)
+
+// Schema of the configuration feature.
+type Schema struct {
+	out string
+}
+
+// New is a constructor for Schema.
+func New() *Schema {
+	return &Schema{}
+}
+
+// Name impl.
+func (s *Schema) Name() string {
+	return "schema"
+}
+
+// Do impl.
+func (s *Schema) Do(c mannequin.Mnqn, args ...string) error {
+	data, err := mannequin.LConfigSchema()
+	if err != nil {
+		return err
+	}
+
+	if s.out == "" {
+		_, err := fmt.Fprintf(c, "%s\n", data)
+		return err
+	}
+
+	if err := ioutil.WriteFile(s.out, append(data, '\n'), 0644); err != nil {
+		return err
+	}
+
+	fmt.Fprintf(c, "Schema is written to %s.\n", s.out)
+	return nil
+}
+
+// Flags impl.
+func (s *Schema) Flags(fs *flag.FlagSet) {
+	fs.StringVar(&s.out, "out", "", "write the schema to the file instead of the output")
+}
+
+// Info impl.
+func (s *Schema) Info() io.Reader {
+	return strings.NewReader("Prints JSON Schema of the local configuration (for the editor autocompletion)")
+}
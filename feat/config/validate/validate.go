@@ -0,0 +1,64 @@
+package validate
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/kostkobv/mannequin"
+)
+
+// Validate configuration feature.
+type Validate struct{}
+
+// New is a constructor for Validate.
+func New() *Validate {
+	return &Validate{}
+}
+
+// Name impl.
+func (v *Validate) Name() string {
+	return "validate"
+}
+
+// Do impl.
+func (v *Validate) Do(c mannequin.Mnqn, args ...string) error {
+	if len(args) > 1 {
+		return errors.New("usage: config validate [FILE]")
+	}
+
+	path := mannequin.DefaultLConfigFileName
+	if len(args) == 1 {
+		path = args[0]
+	}
+
+	ps, err := mannequin.ValidateLConfigFile(path, c.Config)
+	if err != nil {
+		return err
+	}
+
+	if len(ps) == 0 {
+		fmt.Fprintf(c, "%s is valid.\n", path)
+		return nil
+	}
+
+	for _, p := range ps {
+		loc := path
+		if p.Line != 0 {
+			loc = fmt.Sprintf("%s:%d", path, p.Line)
+		}
+		if p.Path != "" {
+			fmt.Fprintf(c, "%s: %s: %s\n", loc, p.Path, p.Msg)
+			continue
+		}
+		fmt.Fprintf(c, "%s: %s\n", loc, p.Msg)
+	}
+
+	return fmt.Errorf("%d problem(s) found in %s", len(ps), path)
+}
+
+// Info impl.
+func (v *Validate) Info() io.Reader {
+	return strings.NewReader("Reports every problem of the local configuration at once ([FILE])")
+}
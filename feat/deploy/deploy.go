@@ -1,23 +1,27 @@
 package deploy
 
 import (
+	"flag"
 	"fmt"
 	"io"
 	"os"
 	"strings"
 
+	"github.com/kostkobv/mannequin/pkg"
 	"github.com/kostkobv/mannequin/pkg/docker"
 
 	"github.com/kostkobv/mannequin/pkg/kubectl"
 
 	"github.com/kostkobv/mannequin"
 	"github.com/kostkobv/mannequin/feat"
-	"github.com/kostkobv/mannequin/pkg/helm"
 )
 
 // Deploy feature.
 type Deploy struct {
 	SubFeats *feat.Feats
+	profile  string
+	dryRun   bool
+	skipLint bool
 }
 
 // New is a constructor for Deploy.
@@ -56,30 +60,142 @@ func (d *Deploy) Do(c mannequin.Mnqn, args ...string) error {
 	}
 	fmt.Fprintf(c, "Found local configuration for \"%s\".\n", lc.Name)
 
-	fmt.Fprintln(c, "Checking global dependencies.")
-	if err := lc.CheckGlobalDepsReady(); err != nil {
+	profile := d.profile
+	if p, err := c.Config.Project(lc.Name); err == nil && profile == "" {
+		profile = p.Profile
+	}
+	if err := lc.ApplyProfile(profile); err != nil {
 		return err
 	}
+	if lc.Profile != "" {
+		fmt.Fprintf(c, "Using profile \"%s\".\n", lc.Profile)
+	}
+	if err := c.SelectContext(lc); err != nil {
+		return fmt.Errorf("couldn't select kubernetes context: %s", err)
+	}
+	c.Debugf("Using kubernetes context \"%s\".\n", c.K8SContext)
+
+	// install ID is persisted only once anything is deployed.
+	install := c.Config.Install
+	switch {
+	case !d.dryRun:
+		if install, err = c.Config.InstallID(); err != nil {
+			return err
+		}
+	case install == "":
+		install = "<generated-on-deploy>"
+	}
 
-	fmt.Fprintf(c, "Setting kubectl context to \"%s\".\n", c.K8SContext)
-	if err := kubectl.UseContext(c.K8SContext); err != nil {
+	dep, err := lc.Deployer(c.K8SContext, c.Namespace, install)
+	if err != nil {
 		return err
 	}
 
-	fmt.Fprintln(c, "Building image.")
-	if err := lc.Docker.GenerateImageName(lc.Name); err != nil {
-		return fmt.Errorf("couldn't generage image name: %s", err)
+	// dry run doesn't touch the cluster, so only the deployment tools are required.
+	if d.dryRun {
+		if err := dep.Check(); err != nil {
+			return err
+		}
+	} else {
+		fmt.Fprintln(c, "Checking global dependencies.")
+		if err := lc.CheckGlobalDepsReady(c.K8SContext); err != nil {
+			return err
+		}
+
+		fmt.Fprintf(c, "Checking kubernetes context \"%s\".\n", c.K8SContext)
+		if err := c.GuardContext(lc); err != nil {
+			return err
+		}
+
+		if err := kubectl.CheckAndUseContext(c.K8SContext); err != nil {
+			return err
+		}
 	}
-	if err := lc.Docker.GenerateVer(); err != nil {
-		return fmt.Errorf("couldn't generate image version: %s", err)
+
+	fmt.Fprintln(c, "Resolving variables.")
+	if err := c.ResolveVars(&lc); err != nil {
+		return err
+	}
+	for n := range c.LocalVars {
+		c.Debugf("Variable \"%s\" is set.\n", n)
+	}
+
+	if !d.skipLint {
+		fmt.Fprintln(c, "Linting manifests.")
+		if err := dep.Lint(c, &c.LocalVars); err != nil {
+			return fmt.Errorf("lint failed: %s", err)
+		}
 	}
 
+	if d.dryRun {
+		return d.plan(c, lc, dep)
+	}
+
+	fmt.Fprintln(c, "Building image.")
 	if err := docker.BuildImage(c, &c.LocalVars, lc.Docker); err != nil {
 		return fmt.Errorf("couldn't build image: %s", err)
 	}
 
 	fmt.Fprintln(c, "Ready to deploy.")
-	return helm.Deploy(c, &c.LocalVars, lc.Helm)
+	if err := dep.Deploy(c, &c.LocalVars); err != nil {
+		return err
+	}
+
+	d.portForwardHints(c, lc)
+
+	return nil
+}
+
+// portForwardHints prints the commands that forward the ports of the deployed resources.
+func (d *Deploy) portForwardHints(c mannequin.Mnqn, lc mannequin.LConfig) {
+	if len(lc.PortForward) == 0 {
+		return
+	}
+
+	fmt.Fprintln(c, "Forward the ports with:")
+	release, namespace := lc.Release()
+	if c.Namespace != "" {
+		namespace = c.Namespace
+	}
+	for _, pf := range lc.PortForward {
+		args := pf.Args(c.K8SContext, c.LocalVars.Replace(namespace), c.LocalVars.Replace(release))
+		fmt.Fprintln(c, "  "+pkg.Cmdline(args))
+	}
+}
+
+// plan prints what would be done without building or deploying anything.
+// Decrypted secrets are masked in the output.
+func (d *Deploy) plan(c mannequin.Mnqn, lc mannequin.LConfig, dep pkg.Deployer) error {
+	dargs, err := docker.BuildArgs(&c.LocalVars, lc.Docker)
+	if err != nil {
+		return fmt.Errorf("couldn't prepare image build: %s", err)
+	}
+
+	w := pkg.NewMaskWriter(c, lc.SecretValues(&c.LocalVars))
+	fmt.Fprintf(w, "Dry run: nothing is built or deployed to \"%s\".\n", c.K8SContext)
+	fmt.Fprintln(w, "Image would be built with:")
+	fmt.Fprintln(w, "----------------------------------------------------")
+	fmt.Fprintln(w, pkg.Cmdline(dargs))
+	fmt.Fprintln(w, "----------------------------------------------------")
+
+	if err := dep.Plan(w, &c.LocalVars); err != nil {
+		w.Flush() // nolint: errcheck
+		return err
+	}
+
+	return w.Flush()
+}
+
+// Flags impl.
+func (d *Deploy) Flags(fs *flag.FlagSet) {
+	fs.StringVar(&d.profile, "profile", "", "profile of the local configuration to apply")
+	fs.BoolVar(&d.dryRun, "dry-run", false, "print what would be built and deployed without changing anything")
+	fs.BoolVar(&d.skipLint, "skip-lint", false, "do not lint the helm chart before deploying")
+}
+
+// Subs impl.
+func (d *Deploy) Subs() *feat.Feats {
+	return d.SubFeats
 }
 
 // Info impl.
@@ -0,0 +1,99 @@
+package down
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/kostkobv/mannequin"
+	"github.com/kostkobv/mannequin/pkg/kubectl"
+)
+
+// Down feature.
+type Down struct {
+	profile string
+}
+
+// New is a constructor for Down.
+func New() *Down {
+	return &Down{}
+}
+
+// Name impl.
+func (d *Down) Name() string {
+	return "down"
+}
+
+// Do impl.
+func (d *Down) Do(c mannequin.Mnqn, args ...string) error {
+	if len(args) != 0 {
+		return errors.New("usage: down [--profile NAME]")
+	}
+
+	lcfile, err := os.Open(mannequin.DefaultLConfigFileName)
+	if err != nil {
+		return err
+	}
+	defer lcfile.Close()
+
+	lc, err := mannequin.NewLConfigFromFile(lcfile)
+	if err != nil {
+		return err
+	}
+
+	profile := d.profile
+	if p, err := c.Config.Project(lc.Name); err == nil && profile == "" {
+		profile = p.Profile
+	}
+	if err := lc.ApplyProfile(profile); err != nil {
+		return err
+	}
+
+	if err := c.SelectContext(lc); err != nil {
+		return fmt.Errorf("couldn't select kubernetes context: %s", err)
+	}
+
+	dep, err := lc.Deployer(c.K8SContext, c.Namespace, c.Config.Install)
+	if err != nil {
+		return err
+	}
+	if err := dep.Check(); err != nil {
+		return err
+	}
+
+	fmt.Fprintf(c, "Checking kubernetes context \"%s\".\n", c.K8SContext)
+	if err := c.GuardContext(lc); err != nil {
+		return err
+	}
+	if err := kubectl.CheckAndUseContext(c.K8SContext); err != nil {
+		return err
+	}
+
+	// variables are resolved the same way as for the deployment,
+	// so exactly the deployed resources are removed.
+	if err := c.ResolveVars(&lc); err != nil {
+		return err
+	}
+
+	fmt.Fprintf(c, "Removing \"%s\" from \"%s\".\n", lc.Name, c.K8SContext)
+	if err := dep.Down(c, &c.LocalVars); err != nil {
+		return err
+	}
+	fmt.Fprintln(c, "Successfully removed!")
+
+	return nil
+}
+
+// Flags impl.
+func (d *Down) Flags(fs *flag.FlagSet) {
+	fs.StringVar(&d.profile, "profile", "", "profile of the local configuration to apply")
+}
+
+// Info impl.
+func (d *Down) Info() io.Reader {
+	return strings.NewReader("Removes everything that is deployed by the project in the same folder " +
+		"from the selected kubernetes context")
+}
@@ -2,6 +2,7 @@ package feat
 
 import (
 	"errors"
+	"flag"
 	"fmt"
 	"io"
 	"os"
@@ -46,7 +47,7 @@ func NewFeats(name string, info func(*Feats) io.Reader, fds ...FeatDoer) (*Feats
 
 // Name of the Feats.
 func (f *Feats) Name() string {
-	return "mnqn"
+	return f.name
 }
 
 // Do handles the call.
@@ -60,7 +61,25 @@ func (f *Feats) Do(c mannequin.Mnqn, args ...string) error {
 	fname := args[0]
 	for n, fd := range f.fs {
 		if n == fname {
-			return fd.Do(c, args[1:]...)
+			rest, err := Parse(&c, fd, args[1:])
+			if err == flag.ErrHelp {
+				fmt.Fprintf(&c, "%s\t\t", fd.Name())
+				io.Copy(&c, fd.Info()) // nolint: errcheck
+				fmt.Fprintln(&c)
+				if p, ok := fd.(Parent); ok {
+					fmt.Fprintln(&c)
+					p.Subs().FeatsInfo(&c)
+				}
+				fmt.Fprintln(&c)
+				fmt.Fprintln(&c, "Flags:")
+				PrintFlags(&c, FlagSet(&c, fd))
+				return nil
+			}
+			if err != nil {
+				return err
+			}
+
+			return fd.Do(c, rest...)
 		}
 	}
 
@@ -71,6 +90,11 @@ func (f *Feats) Do(c mannequin.Mnqn, args ...string) error {
 	return nil
 }
 
+// Subs impl.
+func (f *Feats) Subs() *Feats {
+	return f
+}
+
 // Register the FeatDoer.
 func (f *Feats) Register(fd FeatDoer) error {
 	f.fs[fd.Name()] = fd
@@ -95,11 +119,16 @@ func (f *Feats) Info() io.Reader {
 
 func (f *Feats) FeatsInfo(w io.Writer) {
 	for _, fd := range f.fs {
+		if hidden(fd) {
+			continue
+		}
+
 		fmt.Fprintf(w, "%s\t\t", fd.Name())
 		_, err := io.Copy(w, fd.Info())
 		if err != nil {
 			fmt.Fprintf(w, "Couldn't read info of \"%s\": %s", fd.Name(), err.Error()) // nolint: errcheck
 		}
 		fmt.Fprintln(w)
+		PrintOwnFlags(w, fd)
 	}
 }
@@ -0,0 +1,145 @@
+package feat
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"strings"
+
+	"github.com/kostkobv/mannequin"
+)
+
+// Flagger is implemented by the FeatDoers that accept flags.
+type Flagger interface {
+	Flags(fs *flag.FlagSet)
+}
+
+// Parent is implemented by the FeatDoers that have sub features.
+type Parent interface {
+	Subs() *Feats
+}
+
+// GlobalFlags defines the flags that are accepted by every feature.
+func GlobalFlags(fs *flag.FlagSet, c *mannequin.Mnqn) {
+	fs.StringVar(&c.K8SContext, "context", c.K8SContext, "kubernetes context to use")
+	fs.StringVar(&c.Namespace, "namespace", c.Namespace, "kubernetes namespace to deploy to")
+	fs.StringVar(&c.ConfigPath, "config", c.ConfigPath, "path to the global configuration file")
+	fs.BoolVar(&c.Verbose, "verbose", c.Verbose, "print detailed output")
+}
+
+// Parse the global flags along with the flags of the FeatDoer.
+// Flags could be placed anywhere in between the positional arguments,
+// parsing stops at the first argument that is a name of the sub feature
+// (or after "--").
+// Returns the positional arguments.
+// flag.ErrHelp is returned if help is requested.
+func Parse(c *mannequin.Mnqn, fd FeatDoer, args []string) ([]string, error) {
+	fs := FlagSet(c, fd)
+
+	var subs *Feats
+	if p, ok := fd.(Parent); ok {
+		subs = p.Subs()
+	}
+
+	var pos []string
+	for {
+		if err := fs.Parse(args); err != nil {
+			if err == flag.ErrHelp {
+				return nil, err
+			}
+
+			return nil, fmt.Errorf("%s: %s (see \"%s --help\")", fd.Name(), err, fd.Name())
+		}
+
+		// "--" terminates the flags.
+		if terminated(args, fs.Args()) {
+			return append(pos, fs.Args()...), nil
+		}
+
+		args = fs.Args()
+		if len(args) == 0 {
+			return pos, nil
+		}
+
+		if subs != nil {
+			if _, err := subs.ByName(args[0]); err == nil {
+				return append(pos, args...), nil
+			}
+		}
+
+		pos = append(pos, args[0])
+		args = args[1:]
+	}
+}
+
+// FlagSet returns the global flags along with the flags of the FeatDoer.
+func FlagSet(c *mannequin.Mnqn, fd FeatDoer) *flag.FlagSet {
+	fs := flag.NewFlagSet(fd.Name(), flag.ContinueOnError)
+	fs.SetOutput(ioutil.Discard)
+	fs.Usage = func() {}
+
+	GlobalFlags(fs, c)
+	if fl, ok := fd.(Flagger); ok {
+		fl.Flags(fs)
+	}
+
+	return fs
+}
+
+// LookupFlag returns the value of the flag if it's provided in args.
+// Used to get the flags that are required before the features are parsed.
+func LookupFlag(args []string, name string) (string, bool) {
+	for i, a := range args {
+		if a == "--" {
+			break
+		}
+
+		// commands could share the name with the flag (e.g. config).
+		if !strings.HasPrefix(a, "-") {
+			continue
+		}
+
+		a = strings.TrimLeft(a, "-")
+		switch {
+		case a == name && i+1 < len(args):
+			return args[i+1], true
+		case strings.HasPrefix(a, name+"="):
+			return strings.TrimPrefix(a, name+"="), true
+		}
+	}
+
+	return "", false
+}
+
+// PrintFlags writes the usage of the flags.
+func PrintFlags(w io.Writer, fs *flag.FlagSet) {
+	fs.VisitAll(func(f *flag.Flag) {
+		name, usage := flag.UnquoteUsage(f)
+		if name != "" {
+			name = " " + name
+		}
+		fmt.Fprintf(w, "  --%s%s\t\t%s", f.Name, name, usage)
+		if f.DefValue != "" && f.DefValue != "false" {
+			fmt.Fprintf(w, " (default %s)", f.DefValue)
+		}
+		fmt.Fprintln(w)
+	})
+}
+
+// PrintOwnFlags writes the usage of the flags that are specific for the FeatDoer.
+func PrintOwnFlags(w io.Writer, fd FeatDoer) {
+	fl, ok := fd.(Flagger)
+	if !ok {
+		return
+	}
+
+	fs := flag.NewFlagSet(fd.Name(), flag.ContinueOnError)
+	fl.Flags(fs)
+	PrintFlags(w, fs)
+}
+
+func terminated(args, rest []string) bool {
+	consumed := len(args) - len(rest)
+	return consumed > 0 && args[consumed-1] == "--"
+}
@@ -0,0 +1,126 @@
+package feat
+
+import (
+	"flag"
+	"io"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/kostkobv/mannequin"
+)
+
+// testFeat is the FeatDoer with the own flag and the sub feature.
+type testFeat struct {
+	force bool
+	subs  *Feats
+}
+
+func (f *testFeat) Name() string                              { return "test" }
+func (f *testFeat) Do(c mannequin.Mnqn, args ...string) error { return nil }
+func (f *testFeat) Info() io.Reader                           { return strings.NewReader("") }
+func (f *testFeat) Flags(fs *flag.FlagSet)                    { fs.BoolVar(&f.force, "force", false, "") }
+func (f *testFeat) Subs() *Feats                              { return f.subs }
+
+func TestParse(t *testing.T) {
+	sub := &testFeat{}
+	subs, err := NewFeats("test", func(*Feats) io.Reader { return strings.NewReader("") }, sub)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name    string
+		args    []string
+		pos     []string
+		force   bool
+		verbose bool
+		k8sCtx  string
+		err     string
+	}{
+		{name: "no arguments"},
+		{
+			name: "flags before arguments",
+			args: []string{"--force", "--context", "dev", "a", "b"},
+			pos:  []string{"a", "b"}, force: true, k8sCtx: "dev",
+		},
+		{
+			name: "flags between arguments",
+			args: []string{"a", "--verbose", "b", "--context=dev"},
+			pos:  []string{"a", "b"}, verbose: true, k8sCtx: "dev",
+		},
+		{
+			name: "sub feature stops parsing",
+			args: []string{"--force", "test", "--verbose", "a"},
+			pos:  []string{"test", "--verbose", "a"}, force: true,
+		},
+		{
+			name: "terminated flags",
+			args: []string{"a", "--", "--force", "b"},
+			pos:  []string{"a", "--force", "b"},
+		},
+		{
+			name: "unknown flag",
+			args: []string{"--unknown"},
+			err:  "test: flag provided but not defined: -unknown (see \"test --help\")",
+		},
+		{
+			name: "help",
+			args: []string{"a", "--help"},
+			err:  flag.ErrHelp.Error(),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var c mannequin.Mnqn
+			fd := &testFeat{subs: subs}
+
+			pos, err := Parse(&c, fd, tt.args)
+			if tt.err != "" {
+				if err == nil || err.Error() != tt.err {
+					t.Fatalf("expected error \"%s\", got %v", tt.err, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if !reflect.DeepEqual(pos, tt.pos) {
+				t.Errorf("expected arguments %q, got %q", tt.pos, pos)
+			}
+			if fd.force != tt.force || c.Verbose != tt.verbose || c.K8SContext != tt.k8sCtx {
+				t.Errorf("unexpected flags: force %t, verbose %t, context \"%s\"", fd.force, c.Verbose, c.K8SContext)
+			}
+		})
+	}
+}
+
+func TestLookupFlag(t *testing.T) {
+	tests := []struct {
+		name  string
+		args  []string
+		want  string
+		found bool
+	}{
+		{name: "missing", args: []string{"deploy", "--verbose"}},
+		{name: "separate value", args: []string{"deploy", "--config", "c.yaml"}, want: "c.yaml", found: true},
+		{name: "inline value", args: []string{"--config=c.yaml", "deploy"}, want: "c.yaml", found: true},
+		{name: "single dash", args: []string{"-config", "c.yaml"}, want: "c.yaml", found: true},
+		{name: "empty inline value", args: []string{"--config="}, want: "", found: true},
+		{name: "no value", args: []string{"deploy", "--config"}},
+		{name: "command with the same name", args: []string{"config", "schema"}},
+		{name: "prefix of the other flag", args: []string{"--configuration", "c.yaml"}},
+		{name: "after terminator", args: []string{"deploy", "--", "--config", "c.yaml"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, found := LookupFlag(tt.args, "config")
+			if got != tt.want || found != tt.found {
+				t.Errorf("expected \"%s\" (%t), got \"%s\" (%t)", tt.want, tt.found, got, found)
+			}
+		})
+	}
+}
@@ -1,17 +1,24 @@
 package implode
 
 import (
-	"bufio"
+	"flag"
 	"fmt"
 	"io"
 	"os"
 	"strings"
 
 	"github.com/kostkobv/mannequin"
+	"github.com/kostkobv/mannequin/pkg/docker"
+	"github.com/kostkobv/mannequin/pkg/kubectl"
 )
 
-type Implode struct{}
+// Implode feature.
+type Implode struct {
+	uninstall bool
+	yes       bool
+}
 
+// New is a constructor for Implode.
 func New() *Implode {
 	return &Implode{}
 }
@@ -23,15 +30,40 @@ func (p *Implode) Name() string {
 
 // Do impl.
 func (p *Implode) Do(c mannequin.Mnqn, args ...string) error {
-	fmt.Fprintln(c, "Are you sure you want to purge current configuration? (y/N):")
-	reader := bufio.NewReader(os.Stdin)
-	text, err := reader.ReadString('\n')
+	if err := c.CheckDefaultConfig(); err != nil {
+		return err
+	}
+
+	deps := c.Deployments()
+	images, err := mannequin.Images()
+	if err != nil {
+		fmt.Fprintf(c, "Couldn't list local images: %s\n", err)
+	}
+
+	fmt.Fprintln(c, "Mannequin has created:")
+	if len(deps) == 0 && len(images) == 0 {
+		fmt.Fprintln(c, "  nothing outside of the configuration folder")
+	}
+	for _, d := range deps {
+		fmt.Fprintf(c, "  %s\n", d)
+	}
+	for _, img := range images {
+		fmt.Fprintf(c, "  image %s\n", img)
+	}
+
+	// everything is confirmed first, so nothing is removed if the implosion is declined.
+	uninstall := p.uninstall
+	if !uninstall && !p.yes && (len(deps) != 0 || len(images) != 0) {
+		if uninstall, err = p.confirm(c, "Do you want to uninstall the releases and remove the images? (y/N):"); err != nil {
+			return err
+		}
+	}
+
+	ok, err := p.confirm(c, "Are you sure you want to purge current configuration? (y/N):")
 	if err != nil {
-		fmt.Fprintf(c, "Something went wrong: %s\n", err)
 		return err
 	}
-	text = strings.TrimSpace(text)
-	if text != "y" && text != "Y" {
+	if !ok {
 		fmt.Fprintln(c, "Skipping.")
 		return nil
 	}
@@ -42,15 +74,149 @@ func (p *Implode) Do(c mannequin.Mnqn, args ...string) error {
 		return err
 	}
 
+	backup, err := mannequin.BackupConfig()
+	if err != nil {
+		return fmt.Errorf("couldn't back up configuration: %s", err)
+	}
+	fmt.Fprintf(c, "Configuration is backed up to %s.\n", backup)
+
+	if uninstall {
+		p.cleanup(c, deps, images)
+	}
+
 	if err := os.RemoveAll(path); err != nil {
 		return err
 	}
 
-	fmt.Fprintln(c, "Purged.")
+	fmt.Fprintln(c, "Purged. Run `mnqnctl restore` to bring it back.")
 	return nil
 }
 
+// cleanup uninstalls the releases, removes the images and offers to delete
+// the namespaces that were created for the releases.
+// Failures are reported and don't stop the implosion.
+func (p *Implode) cleanup(c mannequin.Mnqn, deps []mannequin.Deployment, images []string) {
+	wd, err := os.Getwd()
+	if err != nil {
+		fmt.Fprintf(c, "Couldn't get working directory: %s\n", err)
+		return
+	}
+	defer os.Chdir(wd) // nolint: errcheck
+
+	var leftovers []leftover
+	for _, d := range deps {
+		if d.Err != nil {
+			fmt.Fprintf(c, "Skipping %s.\n", d)
+			continue
+		}
+
+		fmt.Fprintf(c, "Uninstalling %s.\n", d)
+		ns, err := p.down(c, d)
+		if err != nil {
+			fmt.Fprintf(c, "Couldn't uninstall \"%s\": %s\n", d.Project.Name, err)
+			continue
+		}
+
+		l := leftover{project: d.Project.Name, k8sCtx: d.Context, namespace: ns}
+		if d.CreatesNamespace() && !l.in(leftovers) {
+			leftovers = append(leftovers, l)
+		}
+	}
+
+	for _, l := range leftovers {
+		ok, err := p.confirm(c, fmt.Sprintf("Namespace \"%s\" of \"%s\" could be created for \"%s\". "+
+			"Do you want to delete it? (y/N):", l.namespace, l.k8sCtx, l.project))
+		if err != nil || !ok {
+			continue
+		}
+
+		if _, err := kubectl.Run(l.k8sCtx, nil, "delete", "namespace", l.namespace, "--ignore-not-found"); err != nil {
+			fmt.Fprintf(c, "Couldn't delete namespace \"%s\": %s\n", l.namespace, err)
+			continue
+		}
+		fmt.Fprintf(c, "Namespace \"%s\" is deleted.\n", l.namespace)
+	}
+
+	if len(images) != 0 {
+		fmt.Fprintln(c, "Removing images.")
+		if err := docker.RemoveImages(c, images...); err != nil {
+			fmt.Fprintln(c, err)
+		}
+	}
+}
+
+// leftover namespace of the removed deployment.
+type leftover struct {
+	project   string
+	k8sCtx    string
+	namespace string
+}
+
+func (l leftover) in(ls []leftover) bool {
+	for _, ll := range ls {
+		if ll.k8sCtx == l.k8sCtx && ll.namespace == l.namespace {
+			return true
+		}
+	}
+
+	return false
+}
+
+// down removes the deployment of the project from it's cluster.
+// Returns the namespace of the deployment with the variables resolved.
+func (p *Implode) down(c mannequin.Mnqn, d mannequin.Deployment) (string, error) {
+	if d.Context == "" {
+		return "", fmt.Errorf("kubernetes context is unknown, run `mnqnctl down` in %s", d.Project.Path)
+	}
+
+	// paths of the local configuration are relative to the project.
+	if err := os.Chdir(d.Project.Path); err != nil {
+		return "", err
+	}
+
+	c.K8SContext = d.Context
+	c.LocalVars = mannequin.LocalVars{}
+
+	dep, err := d.LConfig.Deployer(c.K8SContext, "", c.Config.Install)
+	if err != nil {
+		return "", err
+	}
+	if err := dep.Check(); err != nil {
+		return "", err
+	}
+	if err := c.GuardContext(d.LConfig); err != nil {
+		return "", err
+	}
+	if err := c.ResolveVars(&d.LConfig); err != nil {
+		return "", err
+	}
+
+	return c.LocalVars.Replace(d.Namespace), dep.Down(c, &c.LocalVars)
+}
+
+func (p *Implode) confirm(c mannequin.Mnqn, text string) (bool, error) {
+	if p.yes {
+		return true, nil
+	}
+
+	fmt.Fprintln(c, text)
+	answer, err := c.ReadLine()
+	if err != nil && err != io.EOF {
+		fmt.Fprintf(c, "Something went wrong: %s\n", err)
+		return false, err
+	}
+
+	return answer == "y" || answer == "Y", nil
+}
+
+// Flags impl.
+func (p *Implode) Flags(fs *flag.FlagSet) {
+	fs.BoolVar(&p.uninstall, "uninstall", false, "uninstall the releases of the projects and remove the images")
+	fs.BoolVar(&p.yes, "yes", false, "do not ask for the confirmation")
+}
+
 // Info impl.
 func (p *Implode) Info() io.Reader {
-	return strings.NewReader("Implodes previously made global configuration")
+	return strings.NewReader("Implodes previously made global configuration. " +
+		"Configuration is backed up first and could be restored with `restore`")
 }
@@ -0,0 +1,170 @@
+package initproject
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/kostkobv/mannequin"
+	"github.com/kostkobv/mannequin/pkg/compose"
+)
+
+// composeVar matches ${NAME}, ${NAME:-default} and ${NAME-default} references.
+var composeVar = regexp.MustCompile(`\$\{([A-Za-z_][A-Za-z0-9_]*)(?::?[-?]([^}]*))?\}`)
+
+// composeProject is the project described by the compose file.
+type composeProject struct {
+	file  compose.File
+	built string
+}
+
+// readCompose reads the compose file and fills the answers that could be taken from it.
+// Chart is generated if it's missing.
+func readCompose(w io.Writer, wd string, a *Answers) (*composeProject, error) {
+	path := a.Compose
+	if path == "" {
+		for _, p := range compose.DefaultFilePaths {
+			if _, err := os.Stat(filepath.Join(wd, p)); err == nil {
+				path = p
+				break
+			}
+		}
+	}
+	if path == "" {
+		return nil, fmt.Errorf("compose file is not found (expected one of: %s)", strings.Join(compose.DefaultFilePaths, ", "))
+	}
+	// path is reported the way it's provided.
+	name := path
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(wd, path)
+	}
+
+	f, err := compose.Read(path)
+	if err != nil {
+		return nil, err
+	}
+
+	built, err := f.Built(a.Name)
+	if err != nil {
+		return nil, fmt.Errorf("couldn't find the service of the project in %s: %s", path, err)
+	}
+	fmt.Fprintf(w, "Service \"%s\" is built from the sources.\n", built)
+
+	b := f.Services[built].Build
+	ctx := b.Context
+	if ctx == "" {
+		ctx = "."
+	}
+	if filepath.Clean(ctx) != "." {
+		fmt.Fprintf(w, "Warning: build context of \"%s\" is \"%s\", but images are built in the project root.\n", built, ctx)
+	}
+	if a.Dockerfile == "" {
+		df := b.Dockerfile
+		if df == "" {
+			df = "Dockerfile"
+		}
+		a.Dockerfile = "./" + filepath.ToSlash(filepath.Join(ctx, df))
+
+		if a.sources == nil {
+			a.sources = map[string]string{}
+		}
+		a.sources["dockerfile"] = fmt.Sprintf("build of \"%s\" in %s", built, name)
+	}
+	a.Scaffold = true
+
+	return &composeProject{file: f, built: built}, nil
+}
+
+// apply the services of the compose file to the local configuration:
+// well known images become service dependencies, environment and ports of
+// the built service become helm values and port forwards.
+func (cp *composeProject) apply(w io.Writer, lc *mannequin.LConfig) {
+	for _, n := range cp.file.ServiceNames() {
+		if n == cp.built {
+			continue
+		}
+
+		s := cp.file.Services[n]
+		switch {
+		case s.Build != nil:
+			fmt.Fprintf(w, "Warning: service \"%s\" is built from the sources as well: skipped (register it as a separate project).\n", n)
+			continue
+		case !compose.WellKnown(s.Image):
+			fmt.Fprintf(w, "Warning: image \"%s\" of service \"%s\" is not a well known one: skipped.\n", s.Image, n)
+			continue
+		}
+
+		lc.Deps = append(lc.Deps, mannequin.Dep{Name: n, Type: mannequin.DepService, Image: s.Image})
+		for _, p := range s.Ports {
+			if p.Unresolved != "" {
+				fmt.Fprintf(w, "Warning: port \"%s\" of service \"%s\" references variables: skipped.\n", p.Unresolved, n)
+				continue
+			}
+			lc.PortForward = append(lc.PortForward, mannequin.PortForward{Resource: "svc/" + n, Local: p.Host, Remote: p.Container})
+		}
+		fmt.Fprintf(w, "Service \"%s\" (%s) is added as a dependency.\n", n, s.Image)
+	}
+
+	s := cp.file.Services[cp.built]
+	values := map[string]interface{}{}
+
+	if len(s.Environment) != 0 {
+		env := map[string]interface{}{}
+		for k, v := range s.Environment {
+			env[k] = cp.importVars(v, lc)
+		}
+		values["env"] = env
+		sort.Strings(lc.Vars.Env)
+	}
+
+	for _, p := range s.Ports {
+		if p.Unresolved != "" {
+			fmt.Fprintf(w, "Warning: port \"%s\" of service \"%s\" references variables: skipped.\n", p.Unresolved, cp.built)
+			continue
+		}
+
+		if _, ok := values["service"]; !ok {
+			values["service"] = map[string]interface{}{"targetPort": p.Container}
+		}
+		lc.PortForward = append(lc.PortForward, mannequin.PortForward{Local: p.Host, Remote: p.Container})
+	}
+
+	if len(values) != 0 {
+		if lc.Helm.InlineValues == nil {
+			lc.Helm.InlineValues = map[string]interface{}{}
+		}
+		for k, v := range values {
+			lc.Helm.InlineValues[k] = v
+		}
+	}
+}
+
+// importVars rewrites compose variable references to the mannequin ones.
+// Referenced variables are imported from the environment, defaults become the values.
+func (cp *composeProject) importVars(v string, lc *mannequin.LConfig) string {
+	return composeVar.ReplaceAllStringFunc(v, func(ref string) string {
+		m := composeVar.FindStringSubmatch(ref)
+		name := m[1]
+
+		known := false
+		for _, e := range lc.Vars.Env {
+			known = known || e == name
+		}
+		if !known {
+			lc.Vars.Env = append(lc.Vars.Env, name)
+		}
+
+		if m[2] != "" {
+			if lc.Vars.Values == nil {
+				lc.Vars.Values = map[string]string{}
+			}
+			lc.Vars.Values[name] = m[2]
+		}
+
+		return "$" + name
+	})
+}
@@ -0,0 +1,81 @@
+package initproject
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/kostkobv/mannequin"
+)
+
+func TestComposeApply(t *testing.T) {
+	dir, err := ioutil.TempDir("", "compose")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	if err := ioutil.WriteFile(filepath.Join(dir, "docker-compose.yml"), []byte(`
+services:
+  app:
+    build: .
+    environment:
+      DB_HOST: db
+      DB_PASSWORD: ${DB_PASSWORD:-secret}
+    ports:
+      - "8080:80"
+  db:
+    image: postgres:13
+    ports:
+      - "5432:5432"
+  cache:
+    image: redis
+    ports:
+      - "${REDIS_PORT}:6379"
+  other:
+    image: example/other
+`), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	a := Answers{Name: "app"}
+	cp, err := readCompose(ioutil.Discard, dir, &a)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if a.Dockerfile != "./Dockerfile" {
+		t.Errorf("expected Dockerfile of the build section, got \"%s\"", a.Dockerfile)
+	}
+	if src := a.source("dockerfile"); src != "build of \"app\" in docker-compose.yml" {
+		t.Errorf("expected Dockerfile to be taken from the build section, got \"%s\"", src)
+	}
+
+	var lc mannequin.LConfig
+	cp.apply(ioutil.Discard, &lc)
+
+	wantDeps := mannequin.Deps{
+		{Name: "cache", Type: mannequin.DepService, Image: "redis"},
+		{Name: "db", Type: mannequin.DepService, Image: "postgres:13"},
+	}
+	if !reflect.DeepEqual(lc.Deps, wantDeps) {
+		t.Errorf("expected deps %+v, got %+v", wantDeps, lc.Deps)
+	}
+
+	wantPF := []mannequin.PortForward{
+		{Resource: "svc/db", Local: 5432, Remote: 5432},
+		{Local: 8080, Remote: 80},
+	}
+	if !reflect.DeepEqual(lc.PortForward, wantPF) {
+		t.Errorf("expected port forwards %+v, got %+v", wantPF, lc.PortForward)
+	}
+
+	wantEnv := map[string]interface{}{"DB_HOST": "db", "DB_PASSWORD": "$DB_PASSWORD"}
+	if !reflect.DeepEqual(lc.Helm.InlineValues["env"], wantEnv) {
+		t.Errorf("expected env %v, got %v", wantEnv, lc.Helm.InlineValues["env"])
+	}
+	if lc.Vars.Values["DB_PASSWORD"] != "secret" {
+		t.Errorf("expected default of DB_PASSWORD to become the value, got %v", lc.Vars.Values)
+	}
+}
@@ -1,19 +1,64 @@
 package initproject
 
 import (
-	"bufio"
 	"errors"
+	"flag"
 	"fmt"
 	"io"
+	"io/ioutil"
 	"os"
 	"path"
+	"path/filepath"
 	"strings"
 
 	"github.com/kostkobv/mannequin"
 	"github.com/kostkobv/mannequin/pkg/docker"
+	"github.com/kostkobv/mannequin/pkg/helm"
+	"github.com/kostkobv/mannequin/pkg/scaffold"
+
+	"gopkg.in/yaml.v2"
 )
 
-type InitProject struct{}
+// defaultChartPath is where the helm chart is generated.
+const defaultChartPath = "chart"
+
+// InitProject feature.
+// Every survey question could be answered with the flags or the answers file,
+// so the project could be initialized non-interactively.
+type InitProject struct {
+	answers     Answers
+	answersPath string
+	values      string
+	yes         bool
+	fromCompose bool
+}
+
+// Answers to the survey questions.
+type Answers struct {
+	Name       string           `yaml:"name,omitempty"`
+	Dockerfile string           `yaml:"dockerfile,omitempty"`
+	Chart      string           `yaml:"chart,omitempty"`
+	Values     helm.ValuesFiles `yaml:"values,omitempty"`
+	Namespace  string           `yaml:"namespace,omitempty"`
+	// Scaffold missing Dockerfile and chart without asking.
+	Scaffold bool `yaml:"scaffold,omitempty"`
+	// Ingress is enabled in the scaffolded chart.
+	Ingress bool `yaml:"ingress,omitempty"`
+	// Compose is the path to the compose file the project is configured from.
+	Compose string `yaml:"compose,omitempty"`
+
+	// sources of the answers by the flag name, so the errors point to where the answer is from.
+	sources map[string]string
+}
+
+// source of the answer to the question of the flag: the flag itself or the answers file.
+func (a Answers) source(flag string) string {
+	if src, ok := a.sources[flag]; ok {
+		return src
+	}
+
+	return "--" + flag
+}
 
 // New is the Init constructor.
 func New() *InitProject {
@@ -25,6 +70,20 @@ func (i *InitProject) Name() string {
 	return "init"
 }
 
+// Flags implementation.
+func (i *InitProject) Flags(fs *flag.FlagSet) {
+	fs.StringVar(&i.answers.Name, "name", "", "name of the project (default is the name of the folder)")
+	fs.StringVar(&i.answers.Dockerfile, "dockerfile", "", "relative path to the Dockerfile")
+	fs.StringVar(&i.answers.Chart, "chart", "", "relative path to the helm chart")
+	fs.StringVar(&i.values, "values", "", "comma separated relative paths to the helm values files")
+	fs.BoolVar(&i.answers.Scaffold, "scaffold", false, "generate missing Dockerfile and helm chart without asking")
+	fs.BoolVar(&i.answers.Ingress, "ingress", false, "enable ingress in the generated helm chart")
+	fs.BoolVar(&i.fromCompose, "from-compose", false, "configure the project from docker-compose.yml")
+	fs.StringVar(&i.answers.Compose, "compose-file", "", "path to the compose file (implies --from-compose)")
+	fs.StringVar(&i.answersPath, "answers", "", "path to the yaml file with the answers to the survey")
+	fs.BoolVar(&i.yes, "yes", false, "never prompt: fail if the answer is missing")
+}
+
 // Do implementation.
 func (i *InitProject) Do(c mannequin.Mnqn, args ...string) error {
 	wd, err := os.Getwd()
@@ -32,7 +91,26 @@ func (i *InitProject) Do(c mannequin.Mnqn, args ...string) error {
 		return fmt.Errorf("couldn't get working dir: %s", err)
 	}
 
-	lc, err := i.initLConfig(c, wd, mannequin.DefaultLConfigFileName, path.Base(wd))
+	a, err := i.collectAnswers()
+	if err != nil {
+		return err
+	}
+	// namespace is set by the global flag.
+	if c.Namespace != "" {
+		a.Namespace = c.Namespace
+	}
+	if a.Name == "" {
+		a.Name = path.Base(wd)
+	}
+
+	var cp *composeProject
+	if i.fromCompose || a.Compose != "" {
+		if cp, err = readCompose(c, wd, &a); err != nil {
+			return err
+		}
+	}
+
+	lc, err := i.initLConfig(c, wd, mannequin.DefaultLConfigFileName, a, cp)
 	if err != nil {
 		return fmt.Errorf("couldn't initialize reference file: %s", err)
 	}
@@ -46,30 +124,87 @@ func (i *InitProject) Do(c mannequin.Mnqn, args ...string) error {
 	return nil
 }
 
-func (i *InitProject) initLConfig(c mannequin.Mnqn, wd, fname, pname string) (mannequin.LConfig, error) {
+// collectAnswers from the answers file and the flags.
+// Flags override the answers from the file.
+func (i *InitProject) collectAnswers() (Answers, error) {
+	a := Answers{sources: map[string]string{}}
+	if i.answersPath != "" {
+		data, err := ioutil.ReadFile(i.answersPath)
+		if err != nil {
+			return Answers{}, fmt.Errorf("couldn't read answers: %s", err)
+		}
+		if err := yaml.UnmarshalStrict(data, &a); err != nil {
+			return Answers{}, fmt.Errorf("couldn't parse answers: %s", err)
+		}
+
+		// answers keys match the flags.
+		for flag, set := range map[string]bool{
+			"name":       a.Name != "",
+			"dockerfile": a.Dockerfile != "",
+			"chart":      a.Chart != "",
+			"values":     a.Values != nil,
+		} {
+			if set {
+				a.sources[flag] = fmt.Sprintf("%s of %s", flag, i.answersPath)
+			}
+		}
+	}
+
+	if i.answers.Name != "" {
+		a.Name = i.answers.Name
+		delete(a.sources, "name")
+	}
+	if i.answers.Dockerfile != "" {
+		a.Dockerfile = i.answers.Dockerfile
+		delete(a.sources, "dockerfile")
+	}
+	if i.answers.Compose != "" {
+		a.Compose = i.answers.Compose
+	}
+	if i.answers.Chart != "" {
+		a.Chart = i.answers.Chart
+		delete(a.sources, "chart")
+	}
+	a.Scaffold = a.Scaffold || i.answers.Scaffold
+	a.Ingress = a.Ingress || i.answers.Ingress
+	if i.values != "" {
+		a.Values = nil
+		delete(a.sources, "values")
+		for _, v := range strings.Split(i.values, ",") {
+			if v = strings.TrimSpace(v); v != "" {
+				a.Values = append(a.Values, helm.ValuesFile{Path: v})
+			}
+		}
+	}
+
+	return a, nil
+}
+
+func (i *InitProject) initLConfig(c mannequin.Mnqn, wd, fname string, a Answers, cp *composeProject) (mannequin.LConfig, error) {
 	switch {
 	case wd == "":
 		return mannequin.LConfig{}, errors.New("working directory is required")
 	case fname == "":
 		return mannequin.LConfig{}, errors.New("local configuration file name is required")
-	case pname == "":
+	case a.Name == "":
 		return mannequin.LConfig{}, errors.New("project name is required")
 	}
 
 	path := wd + string(os.PathSeparator) + fname
 	if _, err := os.Stat(path); os.IsNotExist(err) {
-		lc, err := i.lConfigSurvey(c, wd, pname)
+		lc, err := i.lConfigSurvey(c, c.ReadLine, wd, a)
 		if err != nil {
 			return mannequin.LConfig{}, err
 		}
-
-		f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE, 0775)
-		if err != nil {
-			return mannequin.LConfig{}, err
+		if cp != nil {
+			cp.apply(c, &lc)
 		}
-		defer f.Close()
 
-		return lc, lc.Save(f)
+		return lc, lc.Save(path)
+	}
+
+	if cp != nil {
+		fmt.Fprintf(c, "Warning: %s already exists, compose file is ignored.\n", fname)
 	}
 
 	f, err := os.OpenFile(path, os.O_RDWR, 0775)
@@ -87,80 +222,249 @@ func (i *InitProject) Info() io.Reader {
 		"(it is presumed that the command is ran within the project root folder)")
 }
 
-func (i *InitProject) lConfigSurvey(c mannequin.Mnqn, wd, pname string) (mannequin.LConfig, error) {
+// lConfigSurvey asks for the missing answers using the provided output and the line reader of the input.
+// Provided answers are checked but never asked again.
+// Missing Dockerfile and chart are offered to be generated.
+func (i *InitProject) lConfigSurvey(w io.Writer, readLine func() (string, error), wd string, a Answers) (mannequin.LConfig, error) {
 	lc, err := mannequin.NewLConfig()
 	if err != nil {
 		return mannequin.LConfig{}, err
 	}
 
-	lc.Name = pname
-	lc.Docker.File = docker.DefaultFilePath
+	s := survey{w: w, read: readLine, yes: i.yes, scaffold: a.Scaffold}
 
-	err = mannequin.FileExists(lc.Docker.File)
+	lc.Name = a.Name
+
+	lc.Docker.File, err = s.ask(question{
+		text:   fmt.Sprintf("Please provide path to the working Dockerfile (example: %s):", docker.DefaultFilePath),
+		flag:   "dockerfile",
+		source: a.source("dockerfile"),
+		answer: a.Dockerfile,
+		def:    docker.DefaultFilePath,
+		check:  mannequin.FileExists,
+		offer:  "Dockerfile is not found. Generate one?",
+		generate: func() (string, error) {
+			l, err := scaffold.Detect(wd)
+			if err != nil {
+				return "", err
+			}
+			if err := scaffold.Dockerfile(filepath.Join(wd, docker.DefaultFilePath), l); err != nil {
+				return "", err
+			}
+			fmt.Fprintf(w, "Dockerfile for %s is generated: %s\n", l, docker.DefaultFilePath)
+
+			return docker.DefaultFilePath, nil
+		},
+	})
 	if err != nil {
-		fmt.Fprintln(c, "Dockerfile is not found")
-		for {
-			fmt.Fprintf(c, "Please provide path to the working Dockerfile (example: %s):\n", docker.DefaultFilePath)
-			if _, err := fmt.Scanln(&lc.Docker.File); err != nil {
-				fmt.Fprintf(c, "Couldn't read Dockerfile: %s\n", err)
-				continue
+		return mannequin.LConfig{}, err
+	}
+
+	var chartGenerated bool
+	lc.Helm.ChartPath, err = s.ask(question{
+		text:   "Please provide relative path to the Helm chart:",
+		flag:   "chart",
+		source: a.source("chart"),
+		answer: a.Chart,
+		def:    defaultChartPath,
+		check:  mannequin.DirExists,
+		offer:  fmt.Sprintf("Helm chart is not found. Generate one in \"%s\"?", defaultChartPath),
+		generate: func() (string, error) {
+			ingress := a.Ingress
+			if !ingress && !s.yes {
+				var err error
+				if ingress, err = s.confirm("Expose the service via ingress?", false); err != nil {
+					return "", err
+				}
 			}
 
-			if err := mannequin.FileExists(lc.Docker.File); err != nil {
-				fmt.Fprintf(c, "Couldn't find Dockerfile: %s\n", err)
+			if err := scaffold.Chart(filepath.Join(wd, defaultChartPath), a.Name, ingress); err != nil {
+				return "", err
+			}
+			fmt.Fprintf(w, "Helm chart is generated: %s\n", defaultChartPath)
+			chartGenerated = true
+
+			return defaultChartPath, nil
+		},
+	})
+	if err != nil {
+		return mannequin.LConfig{}, err
+	}
+
+	// image of the generated chart is set on deploy.
+	if chartGenerated {
+		lc.Helm.Set = map[string]string{
+			"image.repository": "$" + string(docker.VarDockerImageName),
+			"image.tag":        "$" + string(docker.VarDockerImageVersion),
+		}
+	}
+
+	if a.Values != nil {
+		for _, vf := range a.Values {
+			if vf.Optional {
 				continue
 			}
+			if err := mannequin.FileExists(vf.Path); err != nil {
+				return mannequin.LConfig{}, fmt.Errorf("%s: values file \"%s\": %s", a.source("values"), vf.Path, err)
+			}
+		}
+		lc.Helm.Values = a.Values
+	} else {
+		values, err := s.ask(question{
+			text:     "Please provide relative path to the Helm values (skip, if you don't need values):",
+			flag:     "values",
+			optional: true,
+			check:    mannequin.FileExists,
+		})
+		if err != nil {
+			return mannequin.LConfig{}, err
+		}
+		if values != "" {
+			lc.Helm.Values = helm.ValuesFiles{{Path: values}}
+		}
+	}
+
+	lc.Helm.ReleaseName = a.Name
+	lc.Helm.Namespace = a.Namespace
+
+	if err := lc.Validate(); err != nil {
+		return mannequin.LConfig{}, err
+	}
+
+	return lc, nil
+}
+
+// question of the survey.
+// Source is where the provided answer is from (the flag by default).
+type question struct {
+	text     string
+	flag     string
+	source   string
+	answer   string
+	def      string
+	optional bool
+	check    func(string) error
+	// offer to generate the answer instead of asking for it.
+	offer    string
+	generate func() (string, error)
+}
+
+// survey asks the questions.
+// If yes is set - nothing is asked: default answer is used or error is returned.
+// If scaffold is set - missing files are generated without asking.
+type survey struct {
+	w        io.Writer
+	read     func() (string, error)
+	yes      bool
+	scaffold bool
+}
+
+// ask the question until the valid answer is provided.
+// Provided answer is only checked.
+// Fails if the input is over.
+func (s *survey) ask(q question) (string, error) {
+	if q.answer != "" {
+		if err := q.check(q.answer); err != nil {
+			src := q.source
+			if src == "" {
+				src = "--" + q.flag
+			}
+			return "", fmt.Errorf("%s \"%s\": %s", src, q.answer, err)
+		}
+		return q.answer, nil
+	}
+
+	// default answer is used silently if it's valid.
+	if q.def != "" && q.check(q.def) == nil {
+		return q.def, nil
+	}
+
+	if q.generate != nil && (s.scaffold || !s.yes) {
+		res, err := s.offer(q)
+		switch {
+		case err != nil && (s.yes || s.scaffold):
+			return "", err
+		case err != nil:
+			fmt.Fprintf(s.w, "Couldn't generate: %s\n", err)
+		case res != "":
+			return res, nil
+		}
+	}
 
-			break
+	if s.yes {
+		if q.optional {
+			return "", nil
 		}
+		return "", fmt.Errorf("--%s (or %s of the answers file) is required", q.flag, q.flag)
 	}
 
 	for {
-		fmt.Fprintf(c, "Please provide relative path to the Helm chart:\n")
-		reader := bufio.NewReader(os.Stdin)
-		text, err := reader.ReadString('\n')
+		fmt.Fprintln(s.w, q.text)
+		text, err := s.readLine()
 		if err != nil {
-			fmt.Fprintf(c, "Couldn't read input: %s\n", err)
-			continue
+			return "", err
 		}
-		lc.Helm.ChartPath = strings.TrimSpace(text)
-		if lc.Helm.ChartPath == "" {
-			fmt.Fprintln(c, "Value is required")
+
+		switch {
+		case text == "" && q.optional:
+			return "", nil
+		case text == "":
+			fmt.Fprintln(s.w, "Value is required")
 			continue
 		}
 
-		if err := mannequin.DirExists(lc.Helm.ChartPath); err != nil {
-			fmt.Fprintf(c, "Couldn't find chart dir: %s\n", err)
+		if err := q.check(text); err != nil {
+			fmt.Fprintf(s.w, "Couldn't find \"%s\": %s\n", text, err)
 			continue
 		}
 
-		break
+		return text, nil
 	}
+}
+
+// offer to generate the answer.
+// Returns empty answer if the offer is declined.
+func (s *survey) offer(q question) (string, error) {
+	if !s.scaffold {
+		ok, err := s.confirm(q.offer, true)
+		if err != nil || !ok {
+			return "", err
+		}
+	}
+
+	return q.generate()
+}
+
+// confirm asks the yes/no question.
+func (s *survey) confirm(text string, def bool) (bool, error) {
+	hint := "[y/N]"
+	if def {
+		hint = "[Y/n]"
+	}
+
 	for {
-		fmt.Fprintf(c, "Please provide relative path to the Helm values (skip, if you don't need values):\n")
-		reader := bufio.NewReader(os.Stdin)
-		text, err := reader.ReadString('\n')
+		fmt.Fprintln(s.w, text, hint)
+		answer, err := s.readLine()
 		if err != nil {
-			fmt.Fprintf(c, "Couldn't read input: %s\n", err)
-			continue
-		}
-		lc.Helm.ValuesPath = strings.TrimSpace(text)
-		if lc.Helm.ValuesPath == "" {
-			break
+			return false, err
 		}
 
-		if err := mannequin.FileExists(lc.Helm.ValuesPath); err != nil {
-			fmt.Fprintf(c, "Couldn't find values file: %s\n", err)
-			continue
+		switch strings.ToLower(answer) {
+		case "":
+			return def, nil
+		case "y", "yes":
+			return true, nil
+		case "n", "no":
+			return false, nil
 		}
-
-		break
 	}
-	lc.Helm.ReleaseName = pname
+}
 
-	if err := lc.Validate(); err != nil {
-		return mannequin.LConfig{}, err
+func (s *survey) readLine() (string, error) {
+	text, err := s.read()
+	if err != nil {
+		return "", fmt.Errorf("couldn't read input: %s", err)
 	}
 
-	return lc, nil
+	return text, nil
 }
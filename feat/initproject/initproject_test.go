@@ -0,0 +1,246 @@
+package initproject
+
+import (
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/kostkobv/mannequin/pkg/helm"
+)
+
+func TestCollectAnswers(t *testing.T) {
+	dir, err := ioutil.TempDir("", "initproject")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	answersPath := filepath.Join(dir, "answers.yaml")
+	if err := ioutil.WriteFile(answersPath, []byte("name: app\ndockerfile: file.Dockerfile\nchart: file-chart\nvalues:\n  - file-values.yaml\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	unknownPath := filepath.Join(dir, "unknown.yaml")
+	if err := ioutil.WriteFile(unknownPath, []byte("unknown: true\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name    string
+		i       InitProject
+		want    Answers
+		sources map[string]string
+		err     string
+	}{
+		{
+			name: "flags only",
+			i: InitProject{
+				answers: Answers{Name: "app", Dockerfile: "Dockerfile", Chart: "chart"},
+				values:  "values.yaml, other.yaml,",
+			},
+			want: Answers{
+				Name:       "app",
+				Dockerfile: "Dockerfile",
+				Chart:      "chart",
+				Values:     helm.ValuesFiles{{Path: "values.yaml"}, {Path: "other.yaml"}},
+			},
+			sources: map[string]string{
+				"dockerfile": "--dockerfile",
+				"chart":      "--chart",
+				"values":     "--values",
+			},
+		},
+		{
+			name: "answers file only",
+			i:    InitProject{answersPath: answersPath},
+			want: Answers{
+				Name:       "app",
+				Dockerfile: "file.Dockerfile",
+				Chart:      "file-chart",
+				Values:     helm.ValuesFiles{{Path: "file-values.yaml"}},
+			},
+			sources: map[string]string{
+				"dockerfile": "dockerfile of " + answersPath,
+				"chart":      "chart of " + answersPath,
+				"values":     "values of " + answersPath,
+			},
+		},
+		{
+			name: "flags override answers file",
+			i: InitProject{
+				answersPath: answersPath,
+				answers:     Answers{Dockerfile: "Dockerfile", Scaffold: true},
+				values:      "values.yaml",
+			},
+			want: Answers{
+				Name:       "app",
+				Dockerfile: "Dockerfile",
+				Chart:      "file-chart",
+				Values:     helm.ValuesFiles{{Path: "values.yaml"}},
+				Scaffold:   true,
+			},
+			sources: map[string]string{
+				"dockerfile": "--dockerfile",
+				"chart":      "chart of " + answersPath,
+				"values":     "--values",
+			},
+		},
+		{
+			name: "unknown answer",
+			i:    InitProject{answersPath: unknownPath},
+			err:  "couldn't parse answers",
+		},
+		{
+			name: "missing answers file",
+			i:    InitProject{answersPath: filepath.Join(dir, "missing.yaml")},
+			err:  "couldn't read answers",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a, err := tt.i.collectAnswers()
+			if tt.err != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.err) {
+					t.Fatalf("expected error containing \"%s\", got %v", tt.err, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			for flag, src := range tt.sources {
+				if got := a.source(flag); got != src {
+					t.Errorf("source of %s: expected \"%s\", got \"%s\"", flag, src, got)
+				}
+			}
+
+			a.sources = nil
+			if !reflect.DeepEqual(a, tt.want) {
+				t.Errorf("expected %+v, got %+v", tt.want, a)
+			}
+		})
+	}
+}
+
+func TestLConfigSurvey(t *testing.T) {
+	dir, err := ioutil.TempDir("", "initproject")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	// answers are relative to the working directory.
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chdir(dir); err != nil {
+		t.Fatal(err)
+	}
+	defer os.Chdir(wd)
+
+	// names differ from the defaults, so they are never picked silently.
+	dockerfile, chart, missing := "app.Dockerfile", "app-chart", "missing"
+	if err := ioutil.WriteFile(dockerfile, []byte("FROM scratch\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Mkdir(chart, 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	fromFile := func(flags ...string) map[string]string {
+		res := map[string]string{}
+		for _, f := range flags {
+			res[f] = f + " of answers.yaml"
+		}
+		return res
+	}
+
+	tests := []struct {
+		name  string
+		yes   bool
+		a     Answers
+		input []string
+		err   string
+	}{
+		{
+			name: "answers are provided",
+			yes:  true,
+			a:    Answers{Name: "app", Dockerfile: dockerfile, Chart: chart},
+		},
+		{
+			name:  "answers are read",
+			a:     Answers{Name: "app", Values: helm.ValuesFiles{}},
+			input: []string{"n", dockerfile, "n", chart},
+		},
+		{
+			name: "invalid flag",
+			yes:  true,
+			a:    Answers{Dockerfile: missing, Chart: chart},
+			err:  "--dockerfile \"" + missing + "\"",
+		},
+		{
+			name: "invalid answer from file",
+			yes:  true,
+			a:    Answers{Dockerfile: dockerfile, Chart: missing, sources: fromFile("chart")},
+			err:  "chart of answers.yaml \"" + missing + "\"",
+		},
+		{
+			name: "invalid values from file",
+			yes:  true,
+			a: Answers{
+				Dockerfile: dockerfile,
+				Chart:      chart,
+				Values:     helm.ValuesFiles{{Path: missing}},
+				sources:    fromFile("values"),
+			},
+			err: "values of answers.yaml: values file \"" + missing + "\"",
+		},
+		{
+			name: "required answer",
+			yes:  true,
+			a:    Answers{Dockerfile: dockerfile},
+			err:  "--chart (or chart of the answers file) is required",
+		},
+		{
+			name: "input is closed",
+			a:    Answers{Dockerfile: dockerfile},
+			err:  "couldn't read input",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			input := tt.input
+			readLine := func() (string, error) {
+				if len(input) == 0 {
+					return "", errors.New("EOF")
+				}
+				line := input[0]
+				input = input[1:]
+				return line, nil
+			}
+
+			i := InitProject{yes: tt.yes}
+			lc, err := i.lConfigSurvey(ioutil.Discard, readLine, dir, tt.a)
+			if tt.err != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.err) {
+					t.Fatalf("expected error containing \"%s\", got %v", tt.err, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if lc.Docker.File != dockerfile || lc.Helm.ChartPath != chart {
+				t.Errorf("unexpected answers: %s, %s", lc.Docker.File, lc.Helm.ChartPath)
+			}
+		})
+	}
+}
@@ -0,0 +1,114 @@
+package kcontext
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/kostkobv/mannequin"
+	"github.com/kostkobv/mannequin/feat"
+	"github.com/kostkobv/mannequin/pkg/kubectl"
+)
+
+// Context feature.
+type Context struct{}
+
+// New is a constructor for Context.
+func New() *Context {
+	return &Context{}
+}
+
+// Name impl.
+func (k *Context) Name() string {
+	return "context"
+}
+
+// Do impl.
+func (k *Context) Do(c mannequin.Mnqn, args ...string) error {
+	if len(args) > 1 {
+		return errors.New("usage: context [NAME|-]")
+	}
+
+	lcfile, err := os.Open(mannequin.DefaultLConfigFileName)
+	if err != nil {
+		return err
+	}
+	defer lcfile.Close()
+
+	lc, err := mannequin.NewLConfigFromFile(lcfile)
+	if err != nil {
+		return err
+	}
+
+	prj, err := c.Config.Project(lc.Name)
+	if err != nil {
+		return err
+	}
+
+	// no arguments - list the contexts.
+	if len(args) == 0 {
+		ctxs, err := kubectl.Contexts()
+		if err != nil {
+			return fmt.Errorf("couldn't list kubernetes contexts: %s", err)
+		}
+
+		for _, n := range ctxs {
+			mark := " "
+			if n == prj.Context {
+				mark = "*"
+			}
+			fmt.Fprintf(c, "%s %s\n", mark, n)
+		}
+		if lc.Context != "" {
+			fmt.Fprintf(c, "Local configuration sets context \"%s\".\n", lc.Context)
+		}
+		return nil
+	}
+
+	name := args[0]
+	if name == "-" {
+		name = ""
+	} else if err := known(name); err != nil {
+		return err
+	}
+
+	if err := c.Config.SetContext(lc.Name, name); err != nil {
+		return err
+	}
+
+	if name == "" {
+		fmt.Fprintf(c, "Context of \"%s\" is reset.\n", lc.Name)
+		return nil
+	}
+
+	fmt.Fprintf(c, "Context \"%s\" is remembered for \"%s\".\n", name, lc.Name)
+	return nil
+}
+
+// CompleteArgs impl.
+func (k *Context) CompleteArgs() []feat.Arg {
+	return []feat.Arg{{Kind: feat.ArgContexts, Values: []string{"-"}}}
+}
+
+// Info impl.
+func (k *Context) Info() io.Reader {
+	return strings.NewReader("Lists the kubernetes contexts or remembers the one " +
+		"for the project in the same folder (NAME, \"-\" to reset)")
+}
+
+func known(name string) error {
+	ctxs, err := kubectl.Contexts()
+	if err != nil {
+		return fmt.Errorf("couldn't list kubernetes contexts: %s", err)
+	}
+
+	for _, c := range ctxs {
+		if c == name {
+			return nil
+		}
+	}
+
+	return fmt.Errorf("context \"%s\" is not found in kubeconfig", name)
+}
@@ -1,8 +1,11 @@
 package feat
 
 import (
+	"flag"
 	"fmt"
 	"io"
+
+	"github.com/kostkobv/mannequin"
 )
 
 func info(f *Feats) io.Reader {
@@ -17,6 +20,14 @@ func info(f *Feats) io.Reader {
 
 		f.FeatsInfo(w)
 
+		fmt.Fprintln(w)
+		fmt.Fprintln(w, "Global flags:")
+		fmt.Fprintln(w)
+
+		fs := flag.NewFlagSet(f.Name(), flag.ContinueOnError)
+		GlobalFlags(fs, &mannequin.Mnqn{})
+		PrintFlags(w, fs)
+
 		w.Close()
 	}(f, w)
 
@@ -0,0 +1,95 @@
+package profile
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/kostkobv/mannequin"
+	"github.com/kostkobv/mannequin/feat"
+)
+
+// Profile feature.
+type Profile struct{}
+
+// New is a constructor for Profile.
+func New() *Profile {
+	return &Profile{}
+}
+
+// Name impl.
+func (p *Profile) Name() string {
+	return "profile"
+}
+
+// Do impl.
+func (p *Profile) Do(c mannequin.Mnqn, args ...string) error {
+	if len(args) > 1 {
+		return errors.New("usage: profile [NAME|-]")
+	}
+
+	lcfile, err := os.Open(mannequin.DefaultLConfigFileName)
+	if err != nil {
+		return err
+	}
+	defer lcfile.Close()
+
+	lc, err := mannequin.NewLConfigFromFile(lcfile)
+	if err != nil {
+		return err
+	}
+
+	prj, err := c.Config.Project(lc.Name)
+	if err != nil {
+		return err
+	}
+
+	// no arguments - list the profiles.
+	if len(args) == 0 {
+		if len(lc.Profiles) == 0 {
+			fmt.Fprintf(c, "No profiles are defined for \"%s\".\n", lc.Name)
+			return nil
+		}
+
+		for _, n := range lc.ProfileNames() {
+			mark := " "
+			if n == prj.Profile || (prj.Profile == "" && n == mannequin.DefaultProfile) {
+				mark = "*"
+			}
+			fmt.Fprintf(c, "%s %s\n", mark, n)
+		}
+		return nil
+	}
+
+	name := args[0]
+	if name == "-" {
+		name = ""
+	} else if _, ok := lc.Profiles[name]; !ok {
+		return fmt.Errorf("profile \"%s\" is not defined (available: %s)", name, strings.Join(lc.ProfileNames(), ", "))
+	}
+
+	if err := c.Config.SetProfile(lc.Name, name); err != nil {
+		return err
+	}
+
+	if name == "" {
+		fmt.Fprintf(c, "Default profile of \"%s\" is reset.\n", lc.Name)
+		return nil
+	}
+
+	fmt.Fprintf(c, "Profile \"%s\" is now default for \"%s\".\n", name, lc.Name)
+	return nil
+}
+
+// CompleteArgs impl.
+func (p *Profile) CompleteArgs() []feat.Arg {
+	return []feat.Arg{{Kind: feat.ArgProfiles, Values: []string{"-"}}}
+}
+
+// Info impl.
+func (p *Profile) Info() io.Reader {
+	return strings.NewReader("Lists the profiles of the project in the same folder " +
+		"or persists the default one (NAME, \"-\" to reset)")
+}
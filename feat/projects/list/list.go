@@ -0,0 +1,48 @@
+package list
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/kostkobv/mannequin"
+)
+
+// List projects feature.
+type List struct{}
+
+// New is a constructor for List.
+func New() *List {
+	return &List{}
+}
+
+// Name impl.
+func (l *List) Name() string {
+	return "list"
+}
+
+// Do impl.
+func (l *List) Do(c mannequin.Mnqn, args ...string) error {
+	if len(c.Config.Projects) == 0 {
+		fmt.Fprintln(c, "No projects are registered.")
+		return nil
+	}
+
+	tw := tabwriter.NewWriter(c, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(tw, "NAME\tPATH\tSTATUS")
+	for _, p := range c.Config.Projects {
+		status := "ok"
+		if !p.Exists() {
+			status = "missing"
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\n", p.Name, p.Path, status)
+	}
+
+	return tw.Flush()
+}
+
+// Info impl.
+func (l *List) Info() io.Reader {
+	return strings.NewReader("Lists the registered projects")
+}
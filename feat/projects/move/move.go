@@ -0,0 +1,75 @@
+package move
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/kostkobv/mannequin"
+	"github.com/kostkobv/mannequin/feat"
+)
+
+// Move project feature.
+type Move struct{}
+
+// New is a constructor for Move.
+func New() *Move {
+	return &Move{}
+}
+
+// Name impl.
+func (m *Move) Name() string {
+	return "move"
+}
+
+// Do impl.
+func (m *Move) Do(c mannequin.Mnqn, args ...string) error {
+	if len(args) != 2 {
+		return errors.New("usage: projects move NAME PATH")
+	}
+	name := args[0]
+
+	path, err := filepath.Abs(args[1])
+	if err != nil {
+		return err
+	}
+
+	p := mannequin.Project{Name: name, Path: path}
+	if !p.Exists() {
+		return fmt.Errorf("%s is not found", p.LConfigPath())
+	}
+
+	f, err := os.Open(p.LConfigPath())
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	lc, err := mannequin.NewLConfigFromFile(f)
+	if err != nil {
+		return err
+	}
+	if lc.Name != name {
+		return fmt.Errorf("%s belongs to project \"%s\"", p.LConfigPath(), lc.Name)
+	}
+
+	if err := c.Config.Move(name, path); err != nil {
+		return err
+	}
+
+	fmt.Fprintf(c, "Project \"%s\" is moved to %s.\n", name, path)
+	return nil
+}
+
+// CompleteArgs impl.
+func (m *Move) CompleteArgs() []feat.Arg {
+	return []feat.Arg{{Kind: feat.ArgProjects}, {Kind: feat.ArgFiles}}
+}
+
+// Info impl.
+func (m *Move) Info() io.Reader {
+	return strings.NewReader("Updates the path of the project that has been moved (NAME PATH)")
+}
@@ -0,0 +1,63 @@
+package projects
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/kostkobv/mannequin"
+	"github.com/kostkobv/mannequin/feat"
+)
+
+// Projects feature.
+type Projects struct {
+	SubFeats *feat.Feats
+}
+
+// New is a constructor for Projects.
+func New(fds ...feat.FeatDoer) (*Projects, error) {
+	f, err := feat.NewFeats("projects", info, fds...)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Projects{SubFeats: f}, nil
+}
+
+// Name impl.
+func (p *Projects) Name() string {
+	return "projects"
+}
+
+// Do impl.
+func (p *Projects) Do(c mannequin.Mnqn, args ...string) error {
+	return p.SubFeats.Do(c, args...)
+}
+
+// Subs impl.
+func (p *Projects) Subs() *feat.Feats {
+	return p.SubFeats
+}
+
+// Info impl.
+func (p *Projects) Info() io.Reader {
+	return strings.NewReader("Manages the registered projects")
+}
+
+func info(f *feat.Feats) io.Reader {
+	r, w := io.Pipe()
+	go func(f *feat.Feats, w io.WriteCloser) {
+		fmt.Fprintln(w, "manage the projects registered in the global configuration")
+		fmt.Fprintln(w)
+		fmt.Fprintln(w, "For more information - https://github.com/kostkobv/mannequin")
+		fmt.Fprintln(w)
+		fmt.Fprintln(w, "Available commands:")
+		fmt.Fprintln(w)
+
+		f.FeatsInfo(w)
+
+		w.Close()
+	}(f, w)
+
+	return r
+}
@@ -0,0 +1,46 @@
+package prune
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/kostkobv/mannequin"
+)
+
+// Prune projects feature.
+type Prune struct{}
+
+// New is a constructor for Prune.
+func New() *Prune {
+	return &Prune{}
+}
+
+// Name impl.
+func (p *Prune) Name() string {
+	return "prune"
+}
+
+// Do impl.
+func (p *Prune) Do(c mannequin.Mnqn, args ...string) error {
+	pruned, err := c.Config.Prune()
+	if err != nil {
+		return err
+	}
+
+	if len(pruned) == 0 {
+		fmt.Fprintln(c, "Nothing to prune.")
+		return nil
+	}
+
+	for _, prj := range pruned {
+		fmt.Fprintf(c, "Project \"%s\" (%s) is unregistered.\n", prj.Name, prj.Path)
+	}
+
+	return nil
+}
+
+// Info impl.
+func (p *Prune) Info() io.Reader {
+	return strings.NewReader("Unregisters the projects whose path no longer contains " + mannequin.DefaultLConfigFileName)
+}
@@ -0,0 +1,50 @@
+package remove
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/kostkobv/mannequin"
+	"github.com/kostkobv/mannequin/feat"
+)
+
+// Remove project feature.
+type Remove struct{}
+
+// New is a constructor for Remove.
+func New() *Remove {
+	return &Remove{}
+}
+
+// Name impl.
+func (r *Remove) Name() string {
+	return "remove"
+}
+
+// Do impl.
+func (r *Remove) Do(c mannequin.Mnqn, args ...string) error {
+	if len(args) == 0 {
+		return errors.New("usage: projects remove NAME...")
+	}
+
+	for _, n := range args {
+		if err := c.Config.Unregister(n); err != nil {
+			return err
+		}
+		fmt.Fprintf(c, "Project \"%s\" is unregistered.\n", n)
+	}
+
+	return nil
+}
+
+// CompleteArgs impl.
+func (r *Remove) CompleteArgs() []feat.Arg {
+	return []feat.Arg{{Kind: feat.ArgProjects}}
+}
+
+// Info impl.
+func (r *Remove) Info() io.Reader {
+	return strings.NewReader("Unregisters the projects (NAME...), the project files are left intact")
+}
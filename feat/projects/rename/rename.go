@@ -0,0 +1,80 @@
+package rename
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/kostkobv/mannequin"
+	"github.com/kostkobv/mannequin/feat"
+)
+
+// Rename project feature.
+type Rename struct{}
+
+// New is a constructor for Rename.
+func New() *Rename {
+	return &Rename{}
+}
+
+// Name impl.
+func (r *Rename) Name() string {
+	return "rename"
+}
+
+// Do impl.
+func (r *Rename) Do(c mannequin.Mnqn, args ...string) error {
+	if len(args) != 2 {
+		return errors.New("usage: projects rename NAME NEW_NAME")
+	}
+	name, newName := args[0], args[1]
+
+	p, err := c.Config.Project(name)
+	if err != nil {
+		return err
+	}
+
+	// the name of the local configuration has to match the registered one.
+	if !p.Exists() {
+		if err := c.Config.Rename(name, newName); err != nil {
+			return err
+		}
+		fmt.Fprintf(c, "Project \"%s\" is renamed to \"%s\".\n", name, newName)
+		fmt.Fprintf(c, "%s is not found, rename the project there once it's back.\n", p.LConfigPath())
+		return nil
+	}
+
+	lc, err := mannequin.NewLConfigFromPath(p.LConfigPath())
+	if err != nil {
+		return err
+	}
+
+	if err := c.Config.Rename(name, newName); err != nil {
+		return err
+	}
+
+	// registry is renamed back if the local configuration couldn't be saved, so they still match.
+	lc.Name = newName
+	if err := lc.Save(""); err != nil {
+		if rerr := c.Config.Rename(newName, name); rerr != nil {
+			return fmt.Errorf("couldn't rename the project in %s: %s (and couldn't rename it back in the registry: %s)",
+				p.LConfigPath(), err, rerr)
+		}
+		return fmt.Errorf("couldn't rename the project in %s: %s", p.LConfigPath(), err)
+	}
+	fmt.Fprintf(c, "Project \"%s\" is renamed to \"%s\".\n", name, newName)
+	fmt.Fprintf(c, "%s is updated.\n", p.LConfigPath())
+
+	return nil
+}
+
+// CompleteArgs impl.
+func (r *Rename) CompleteArgs() []feat.Arg {
+	return []feat.Arg{{Kind: feat.ArgProjects}, {Kind: feat.ArgNone}}
+}
+
+// Info impl.
+func (r *Rename) Info() io.Reader {
+	return strings.NewReader("Renames the project both in the registry and in it's local configuration (NAME NEW_NAME)")
+}
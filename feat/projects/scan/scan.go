@@ -0,0 +1,101 @@
+package scan
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/kostkobv/mannequin"
+)
+
+// Scan projects feature.
+type Scan struct{}
+
+// New is a constructor for Scan.
+func New() *Scan {
+	return &Scan{}
+}
+
+// Name impl.
+func (s *Scan) Name() string {
+	return "scan"
+}
+
+// Do impl.
+func (s *Scan) Do(c mannequin.Mnqn, args ...string) error {
+	if len(args) != 1 {
+		return errors.New("usage: projects scan DIR")
+	}
+
+	fmt.Fprintf(c, "Scanning %s.\n", args[0])
+	found, problems, err := mannequin.FindProjects(args[0])
+	if err != nil {
+		return err
+	}
+	for _, err := range problems {
+		fmt.Fprintf(c, "Skipping %s.\n", err)
+	}
+
+	// projects with the same name can't be registered.
+	byName := map[string][]mannequin.Project{}
+	for _, p := range found {
+		byName[p.Name] = append(byName[p.Name], p)
+	}
+
+	var registered, moved, skipped int
+	for _, p := range found {
+		if p.Name == "" {
+			fmt.Fprintf(c, "Skipping %s: project name is not set.\n", p.LConfigPath())
+			skipped++
+			continue
+		}
+
+		if same := byName[p.Name]; len(same) > 1 {
+			fmt.Fprintf(c, "Skipping %s: name \"%s\" collides with %s.\n", p.Path, p.Name, others(same, p))
+			skipped++
+			continue
+		}
+
+		prj, err := c.Config.Project(p.Name)
+		switch {
+		case err != nil:
+			if err := c.Config.Register(p); err != nil {
+				return err
+			}
+			fmt.Fprintf(c, "Project \"%s\" (%s) is registered.\n", p.Name, p.Path)
+			registered++
+		case prj.Path == p.Path:
+			// already registered.
+		case prj.Exists():
+			fmt.Fprintf(c, "Skipping %s: name \"%s\" is already registered for %s.\n", p.Path, p.Name, prj.Path)
+			skipped++
+		default:
+			if err := c.Config.Move(p.Name, p.Path); err != nil {
+				return err
+			}
+			fmt.Fprintf(c, "Project \"%s\" is moved from %s to %s.\n", p.Name, prj.Path, p.Path)
+			moved++
+		}
+	}
+
+	fmt.Fprintf(c, "Found %d project(s): %d registered, %d moved, %d skipped.\n",
+		len(found)+len(problems), registered, moved, skipped+len(problems))
+	return nil
+}
+
+// Info impl.
+func (s *Scan) Info() io.Reader {
+	return strings.NewReader("Finds every " + mannequin.DefaultLConfigFileName + " within the dir tree and registers the projects (DIR)")
+}
+
+func others(ps []mannequin.Project, p mannequin.Project) string {
+	var res []string
+	for _, pp := range ps {
+		if pp.Path != p.Path {
+			res = append(res, pp.Path)
+		}
+	}
+
+	return strings.Join(res, ", ")
+}
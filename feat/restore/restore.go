@@ -0,0 +1,85 @@
+package restore
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strings"
+
+	"github.com/kostkobv/mannequin"
+	"github.com/kostkobv/mannequin/feat"
+)
+
+// Restore feature.
+type Restore struct{}
+
+// New is a constructor for Restore.
+func New() *Restore {
+	return &Restore{}
+}
+
+// Name impl.
+func (r *Restore) Name() string {
+	return "restore"
+}
+
+// Do impl.
+func (r *Restore) Do(c mannequin.Mnqn, args ...string) error {
+	switch len(args) {
+	case 0:
+		return r.list(c)
+	case 1:
+	default:
+		return errors.New("usage: restore [BACKUP]")
+	}
+
+	if err := c.CheckDefaultConfig(); err != nil {
+		return err
+	}
+
+	path, err := mannequin.FindBackup(args[0])
+	if err != nil {
+		return err
+	}
+
+	replaced, err := mannequin.RestoreBackup(path)
+	if err != nil {
+		return fmt.Errorf("couldn't restore %s: %s", path, err)
+	}
+	if replaced != "" {
+		fmt.Fprintf(c, "Replaced configuration is backed up to %s.\n", replaced)
+	}
+	fmt.Fprintf(c, "Configuration is restored from %s.\n", path)
+
+	return nil
+}
+
+func (r *Restore) list(c mannequin.Mnqn) error {
+	bs, err := mannequin.Backups()
+	if err != nil {
+		return err
+	}
+	if len(bs) == 0 {
+		fmt.Fprintln(c, "No backups are found.")
+		return nil
+	}
+
+	fmt.Fprintln(c, "Available backups (the latest is the last):")
+	for _, b := range bs {
+		fmt.Fprintf(c, "  %s\n", strings.TrimSuffix(filepath.Base(b), ".tar.gz"))
+	}
+	fmt.Fprintln(c, "Run `mnqnctl restore BACKUP` to restore one of them.")
+
+	return nil
+}
+
+// CompleteArgs impl.
+func (r *Restore) CompleteArgs() []feat.Arg {
+	return []feat.Arg{{Kind: feat.ArgBackups}}
+}
+
+// Info impl.
+func (r *Restore) Info() io.Reader {
+	return strings.NewReader("Restores the global configuration from the backup made by implode")
+}
@@ -0,0 +1,175 @@
+package edit
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"strings"
+
+	"github.com/kostkobv/mannequin"
+	"github.com/kostkobv/mannequin/pkg/secrets"
+
+	"gopkg.in/yaml.v2"
+)
+
+const defaultEditor = "vi"
+
+// Edit secrets feature.
+type Edit struct{}
+
+// New is a constructor for Edit.
+func New() *Edit {
+	return &Edit{}
+}
+
+// Name impl.
+func (e *Edit) Name() string {
+	return "edit"
+}
+
+// Do impl.
+func (e *Edit) Do(c mannequin.Mnqn, args ...string) error {
+	if len(args) > 1 {
+		return errors.New("usage: secrets edit [FILE]")
+	}
+
+	k, err := c.SecretsKey(true)
+	if err != nil {
+		return err
+	}
+
+	if len(args) == 1 {
+		return e.editFile(c, k, args[0])
+	}
+
+	return e.editLConfig(c, k)
+}
+
+// Info impl.
+func (e *Edit) Info() io.Reader {
+	return strings.NewReader("Opens the decrypted secrets of the local configuration " +
+		"(or of the provided helm values file) in $EDITOR and encrypts them back")
+}
+
+func (e *Edit) editLConfig(c mannequin.Mnqn, k *secrets.Key) error {
+	lc, err := mannequin.NewLConfigFromPath(mannequin.DefaultLConfigFileName)
+	if err != nil {
+		return err
+	}
+
+	plain := map[string]string{}
+	for n, v := range lc.Secrets {
+		// values that are not encrypted yet are shown as they are to be encrypted on save.
+		if !secrets.IsEncrypted(v) {
+			plain[n] = v
+			continue
+		}
+		if plain[n], err = k.Decrypt(v); err != nil {
+			return fmt.Errorf("secret \"%s\": %s", n, err)
+		}
+	}
+
+	var doc []byte
+	if len(plain) != 0 {
+		if doc, err = yaml.Marshal(plain); err != nil {
+			return err
+		}
+	}
+
+	doc, err = edit(doc)
+	if err != nil {
+		return err
+	}
+
+	plain = map[string]string{}
+	if err := yaml.Unmarshal(doc, &plain); err != nil {
+		return fmt.Errorf("secrets are expected to be a map of names and values: %s", err)
+	}
+
+	lc.Secrets = map[string]string{}
+	for n, v := range plain {
+		if lc.Secrets[n], err = k.Encrypt(v); err != nil {
+			return fmt.Errorf("secret \"%s\": %s", n, err)
+		}
+	}
+
+	if err := lc.Save(""); err != nil {
+		return err
+	}
+
+	fmt.Fprintf(c, "Secrets of \"%s\" are saved.\n", lc.Name)
+	return nil
+}
+
+func (e *Edit) editFile(c mannequin.Mnqn, k *secrets.Key, path string) error {
+	doc, err := ioutil.ReadFile(path)
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+
+	if len(bytes.TrimSpace(doc)) != 0 {
+		if doc, err = k.OpenValues(doc); err != nil {
+			return fmt.Errorf("couldn't decrypt \"%s\": %s", path, err)
+		}
+	}
+
+	doc, err = edit(doc)
+	if err != nil {
+		return err
+	}
+
+	if len(bytes.TrimSpace(doc)) != 0 {
+		if doc, err = k.EncryptValues(doc); err != nil {
+			return fmt.Errorf("couldn't encrypt \"%s\": %s", path, err)
+		}
+	}
+
+	if err := ioutil.WriteFile(path, doc, 0600); err != nil {
+		return err
+	}
+
+	fmt.Fprintf(c, "Secrets are saved to \"%s\".\n", path)
+	return nil
+}
+
+// edit the document in the editor set by $VISUAL or $EDITOR.
+// Temporary file is removed right after the editor is closed.
+func edit(doc []byte) ([]byte, error) {
+	tmp, err := ioutil.TempFile("", "mnqn-secrets-*.yaml")
+	if err != nil {
+		return nil, err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(doc); err != nil {
+		tmp.Close()
+		return nil, err
+	}
+	if err := tmp.Close(); err != nil {
+		return nil, err
+	}
+
+	editor := os.Getenv("VISUAL")
+	if editor == "" {
+		editor = os.Getenv("EDITOR")
+	}
+	if editor == "" {
+		editor = defaultEditor
+	}
+
+	args := append(strings.Fields(editor), tmp.Name())
+	cmd := exec.Command(args[0], args[1:]...)
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+
+	if err := cmd.Run(); err != nil {
+		return nil, fmt.Errorf("editor failed: %s", err)
+	}
+
+	return ioutil.ReadFile(tmp.Name())
+}
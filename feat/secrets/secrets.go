@@ -0,0 +1,63 @@
+package secrets
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/kostkobv/mannequin"
+	"github.com/kostkobv/mannequin/feat"
+)
+
+// Secrets feature.
+type Secrets struct {
+	SubFeats *feat.Feats
+}
+
+// New is a constructor for Secrets.
+func New(fds ...feat.FeatDoer) (*Secrets, error) {
+	f, err := feat.NewFeats("secrets", info, fds...)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Secrets{SubFeats: f}, nil
+}
+
+// Name impl.
+func (s *Secrets) Name() string {
+	return "secrets"
+}
+
+// Do impl.
+func (s *Secrets) Do(c mannequin.Mnqn, args ...string) error {
+	return s.SubFeats.Do(c, args...)
+}
+
+// Subs impl.
+func (s *Secrets) Subs() *feat.Feats {
+	return s.SubFeats
+}
+
+// Info impl.
+func (s *Secrets) Info() io.Reader {
+	return strings.NewReader("Manages encrypted secrets of the project in the same folder")
+}
+
+func info(f *feat.Feats) io.Reader {
+	r, w := io.Pipe()
+	go func(f *feat.Feats, w io.WriteCloser) {
+		fmt.Fprintln(w, "manage encrypted secrets of the project in the same folder")
+		fmt.Fprintln(w)
+		fmt.Fprintln(w, "For more information - https://github.com/kostkobv/mannequin")
+		fmt.Fprintln(w)
+		fmt.Fprintln(w, "Available commands:")
+		fmt.Fprintln(w)
+
+		f.FeatsInfo(w)
+
+		w.Close()
+	}(f, w)
+
+	return r
+}
@@ -0,0 +1,74 @@
+package set
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/kostkobv/mannequin"
+)
+
+// Set secret feature.
+type Set struct{}
+
+// New is a constructor for Set.
+func New() *Set {
+	return &Set{}
+}
+
+// Name impl.
+func (s *Set) Name() string {
+	return "set"
+}
+
+// Do impl.
+func (s *Set) Do(c mannequin.Mnqn, args ...string) error {
+	if len(args) == 0 || len(args) > 2 {
+		return errors.New("usage: secrets set NAME [VALUE]")
+	}
+	name := args[0]
+
+	lc, err := mannequin.NewLConfigFromPath(mannequin.DefaultLConfigFileName)
+	if err != nil {
+		return err
+	}
+
+	k, err := c.SecretsKey(true)
+	if err != nil {
+		return err
+	}
+
+	// value is read from the input if not provided to keep it out of the shell history.
+	var val string
+	if len(args) == 2 {
+		val = args[1]
+	} else {
+		fmt.Fprintf(c, "Please provide the value for \"%s\":\n", name)
+		if val, err = c.ReadLine(); err != nil {
+			return fmt.Errorf("couldn't read input: %s", err)
+		}
+	}
+
+	enc, err := k.Encrypt(val)
+	if err != nil {
+		return fmt.Errorf("couldn't encrypt the value: %s", err)
+	}
+
+	if lc.Secrets == nil {
+		lc.Secrets = map[string]string{}
+	}
+	lc.Secrets[name] = enc
+
+	if err := lc.Save(""); err != nil {
+		return err
+	}
+
+	fmt.Fprintf(c, "Secret \"%s\" is set.\n", name)
+	return nil
+}
+
+// Info impl.
+func (s *Set) Info() io.Reader {
+	return strings.NewReader("Encrypts the value and stores it as a secret variable in the local configuration (NAME [VALUE])")
+}
@@ -5,24 +5,45 @@ import (
 	"fmt"
 	"io"
 	"os"
-	"os/user"
+	"path/filepath"
+	"runtime"
 )
 
 const (
-	configPath = ".mnqn"
-	configFile = "config.yaml"
-
-	pathSep = os.PathSeparator
+	// legacyConfigPath is the configuration folder in the home dir used before XDG support.
+	legacyConfigPath = ".mnqn"
+	configFile       = "config.yaml"
+	appDir           = "mnqn"
+
+	// HomeEnv is the environment variable that overrides the folder everything is stored in.
+	// Configuration and state are stored in the config and state subfolders of it.
+	HomeEnv = "MNQN_HOME"
 )
 
-// ConfigFolderPath returns expected path for the configuration folder.
+// ConfigFolderPath returns expected path for the configuration folder
+// (global configuration and secrets key):
+//  1. $MNQN_HOME/config;
+//  2. ~/.mnqn if it exists;
+//  3. $XDG_CONFIG_HOME/mnqn (~/.config/mnqn by default).
 func ConfigFolderPath() (string, error) {
-	usr, err := user.Current()
+	if h := os.Getenv(HomeEnv); h != "" {
+		return filepath.Join(h, "config"), nil
+	}
+
+	home, err := os.UserHomeDir()
 	if err != nil {
 		return "", err
 	}
+	if legacy := filepath.Join(home, legacyConfigPath); dirExists(legacy) {
+		return legacy, nil
+	}
 
-	return usr.HomeDir + string(pathSep) + configPath + string(pathSep), nil
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", err
+	}
+
+	return filepath.Join(dir, appDir), nil
 }
 
 // ConfigPath returns expected path for the configuration file.
@@ -32,7 +53,60 @@ func ConfigPath() (string, error) {
 		return "", err
 	}
 
-	return folderPath + configFile, err
+	return filepath.Join(folderPath, configFile), nil
+}
+
+// CheckDefaultConfig fails if the global configuration is not the one of the configuration folder
+// (provided with --config), so the commands that replace the whole folder don't touch the wrong one.
+func (m Mnqn) CheckDefaultConfig() error {
+	path, err := ConfigPath()
+	if err != nil {
+		return err
+	}
+
+	custom, err := filepath.Abs(m.ConfigPath)
+	if err != nil {
+		return err
+	}
+	if def, err := filepath.Abs(path); err != nil || custom != def {
+		return fmt.Errorf("configuration %s is provided with --config: "+
+			"only the configuration folder %s is supported", m.ConfigPath, filepath.Dir(path))
+	}
+
+	return nil
+}
+
+// StateFolderPath returns expected path for the data that should persist
+// but doesn't belong to the configuration (e.g. configuration backups):
+// $MNQN_HOME/state or $XDG_STATE_HOME/mnqn (~/.local/state/mnqn by default).
+func StateFolderPath() (string, error) {
+	if h := os.Getenv(HomeEnv); h != "" {
+		return filepath.Join(h, "state"), nil
+	}
+	if dir := os.Getenv("XDG_STATE_HOME"); dir != "" {
+		return filepath.Join(dir, appDir), nil
+	}
+
+	if runtime.GOOS == "windows" {
+		dir, err := os.UserCacheDir()
+		if err != nil {
+			return "", err
+		}
+
+		return filepath.Join(dir, appDir, "state"), nil
+	}
+
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+
+	return filepath.Join(home, ".local", "state", appDir), nil
+}
+
+func dirExists(path string) bool {
+	i, err := os.Stat(path)
+	return err == nil && i.IsDir()
 }
 
 // CheckInited configuration for the whole client.
@@ -77,18 +151,14 @@ func Initialize(out io.Writer) error {
 	}
 
 	if _, err := os.Stat(absPath); os.IsNotExist(err) {
-		var f *os.File
-		if f, err = os.OpenFile(absPath, os.O_WRONLY|os.O_CREATE, 0775); err != nil {
-			return fmt.Errorf("couldn't create config file: %s", err)
-		}
-		defer f.Close()
-
 		mnqn, err := New(out, nil)
 		if err != nil {
 			return err
 		}
 
-		return mnqn.Config.Save(f)
+		if err := mnqn.Config.save(absPath); err != nil {
+			return fmt.Errorf("couldn't create config file: %s", err)
+		}
 	}
 
 	return nil
@@ -0,0 +1,99 @@
+package mannequin
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/kostkobv/mannequin/pkg/docker"
+)
+
+// Deployment of the registered project as it's known from the configuration.
+type Deployment struct {
+	Project   Project
+	LConfig   LConfig
+	Release   string
+	Namespace string
+	// Context is empty if it's not known from the configuration.
+	Context string
+	// Err is set if the local configuration couldn't be read, so the deployment is unknown.
+	Err error
+}
+
+// String impl.
+func (d Deployment) String() string {
+	if d.Err != nil {
+		return fmt.Sprintf("%s: unknown (%s), clean up manually in %s", d.Project.Name, d.Err, d.Project.Path)
+	}
+
+	ns := d.Namespace
+	if ns == "" {
+		ns = "default"
+	}
+	k8sCtx := d.Context
+	if k8sCtx == "" {
+		k8sCtx = "unknown context"
+	}
+
+	return fmt.Sprintf("%s: %s release \"%s\" in namespace \"%s\" of \"%s\"",
+		d.Project.Name, d.LConfig.DeployType(), d.Release, ns, k8sCtx)
+}
+
+// Deployments of the registered projects.
+// Projects with missing or invalid local configuration are listed with the Err.
+func (m *Mnqn) Deployments() []Deployment {
+	var res []Deployment
+	for _, p := range m.Config.Projects {
+		lc, err := NewLConfigFromPath(filepath.Join(p.Path, DefaultLConfigFileName))
+		if err == nil {
+			err = lc.ApplyProfile(p.Profile)
+		}
+		if err == nil {
+			err = lc.Validate()
+		}
+		if err != nil {
+			res = append(res, Deployment{Project: p, Err: err})
+			continue
+		}
+
+		d := Deployment{Project: p, LConfig: lc, Context: lc.Context}
+		d.Release, d.Namespace = lc.Release()
+		if d.Context == "" {
+			d.Context = p.Context
+		}
+		if d.Context == "" {
+			d.Context = m.Config.Context
+		}
+
+		res = append(res, d)
+	}
+
+	return res
+}
+
+// systemNamespaces are never offered for deletion.
+var systemNamespaces = []string{"default", "kube-system", "kube-public", "kube-node-lease"}
+
+// CreatesNamespace returns true if the namespace of the deployment is created on deploy if it's missing,
+// so it could be left behind once the deployment is removed.
+func (d Deployment) CreatesNamespace() bool {
+	if d.Err != nil || d.LConfig.DeployType() != DeployHelm || d.Namespace == "" {
+		return false
+	}
+	if cn := d.LConfig.Helm.CreateNamespace; cn != nil && !*cn {
+		return false
+	}
+
+	for _, ns := range systemNamespaces {
+		if d.Namespace == ns {
+			return false
+		}
+	}
+
+	return true
+}
+
+// Images built by mannequin that are present locally.
+func Images() ([]string, error) {
+	return docker.Images(strings.Replace(docker.DefaultImageNameTmplt, "%s", "*", 1))
+}
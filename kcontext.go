@@ -0,0 +1,127 @@
+package mannequin
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+
+	"github.com/kostkobv/mannequin/pkg/kubectl"
+	"github.com/kostkobv/mannequin/pkg/safety"
+)
+
+// SelectContext sets K8SContext if it's not set yet (e.g. by the flag).
+// Context is looked up in the following order: local configuration, context
+// remembered for the project, global configuration.
+// If none is set - context is picked from the kubeconfig interactively and
+// remembered for the project.
+func (m *Mnqn) SelectContext(lc LConfig) error {
+	if m.K8SContext != "" {
+		return nil
+	}
+
+	if lc.Context != "" {
+		m.K8SContext = lc.Context
+		return nil
+	}
+
+	prj, prjErr := m.Config.Project(lc.Name)
+	if prjErr == nil && prj.Context != "" {
+		m.K8SContext = prj.Context
+		return nil
+	}
+
+	if m.Config.Context != "" {
+		m.K8SContext = m.Config.Context
+		return nil
+	}
+
+	k8sCtx, err := m.PickContext()
+	if err != nil {
+		return err
+	}
+	m.K8SContext = k8sCtx
+
+	if prjErr != nil {
+		return nil
+	}
+
+	if err := m.Config.SetContext(lc.Name, k8sCtx); err != nil {
+		return fmt.Errorf("couldn't remember context: %s", err)
+	}
+	fmt.Fprintf(m.w, "Context \"%s\" is remembered for \"%s\".\n", k8sCtx, lc.Name)
+
+	return nil
+}
+
+// PickContext asks to choose one of the contexts available in kubeconfig.
+func (m *Mnqn) PickContext() (string, error) {
+	ctxs, err := kubectl.Contexts()
+	if err != nil {
+		return "", fmt.Errorf("couldn't list kubernetes contexts: %s", err)
+	}
+	if len(ctxs) == 0 {
+		return "", errors.New("no kubernetes contexts are found in kubeconfig")
+	}
+
+	fmt.Fprintln(m.w, "Kubernetes context is not set. Available contexts:")
+	for i, c := range ctxs {
+		fmt.Fprintf(m.w, "  %d) %s\n", i+1, c)
+	}
+
+	for {
+		fmt.Fprintln(m.w, "Please choose the context (number or name):")
+		text, err := m.ReadLine()
+		if err != nil {
+			return "", fmt.Errorf("couldn't read input: %s", err)
+		}
+
+		if n, err := strconv.Atoi(text); err == nil && n > 0 && n <= len(ctxs) {
+			return ctxs[n-1], nil
+		}
+		for _, c := range ctxs {
+			if c == text {
+				return c, nil
+			}
+		}
+
+		fmt.Fprintf(m.w, "Unknown context \"%s\"\n", text)
+	}
+}
+
+// GuardContext refuses the context if it points to the remote cluster,
+// unless it's allowed in the global configuration.
+// Context of the minikube profile of the project is local as well.
+// Deployment to the allowed remote context has to be confirmed by typing it's name.
+func (m *Mnqn) GuardContext(lc LConfig) error {
+	server, err := kubectl.Server(m.K8SContext)
+	if err != nil {
+		return err
+	}
+
+	var localCtxs []string
+	if lc.Minikube.IsContext(m.K8SContext) {
+		localCtxs = append(localCtxs, m.K8SContext)
+	}
+	if safety.Classify(m.K8SContext, server, localCtxs...) == safety.Local {
+		return nil
+	}
+
+	if !safety.Allowed(m.K8SContext, m.Config.AllowedContexts) {
+		return fmt.Errorf("context \"%s\" points to the remote cluster (%s): "+
+			"add it to allowed_contexts of the global configuration to deploy there", m.K8SContext, server)
+	}
+
+	fmt.Fprintf(m.w, "Context \"%s\" points to the remote cluster (%s).\n", m.K8SContext, server)
+	fmt.Fprintln(m.w, "Please type the name of the context to confirm:")
+	text, err := m.ReadLine()
+	if err != nil && err != io.EOF {
+		return fmt.Errorf("couldn't read input: %s", err)
+	}
+
+	if text != m.K8SContext {
+		return errors.New("deployment to the remote cluster is not confirmed")
+	}
+
+	return nil
+}
@@ -8,6 +8,8 @@ import (
 	"github.com/kostkobv/mannequin/pkg/docker"
 	"github.com/kostkobv/mannequin/pkg/helm"
 	"github.com/kostkobv/mannequin/pkg/kubectl"
+	"github.com/kostkobv/mannequin/pkg/kustomize"
+	"github.com/kostkobv/mannequin/pkg/manifests"
 	"github.com/kostkobv/mannequin/pkg/minikube"
 
 	"gopkg.in/yaml.v2"
@@ -30,12 +32,31 @@ var validDepTypes = []DepType{DepProject, DepService}
 
 // LConfig represents local configuration of the project.
 type LConfig struct {
-	Version string         `yaml:"version,flow"`
-	Name    string         `yaml:"name,flow"`
-	Docker  docker.LConfig `yaml:"docker,flow"`
-	Helm    helm.LConfig   `yaml:"helm,flow"`
-	Deps    Deps           `yaml:"deps,omitempty,flow"`
-	file    *os.File
+	Version string            `yaml:"version,flow"`
+	Name    string            `yaml:"name,flow"`
+	Context string            `yaml:"context,omitempty,flow"`
+	Docker  docker.LConfig    `yaml:"docker,flow"`
+	Helm    helm.LConfig      `yaml:"helm,omitempty,flow"`
+	Deps    Deps              `yaml:"deps,omitempty,flow"`
+	Vars    VarSources        `yaml:"vars,omitempty,flow"`
+	Secrets map[string]string `yaml:"secrets,omitempty,flow"`
+
+	PortForward []PortForward `yaml:"port_forward,omitempty"`
+
+	Deploy    DeployConfig      `yaml:"deploy,omitempty,flow"`
+	Kustomize kustomize.LConfig `yaml:"kustomize,omitempty,flow"`
+	Manifests manifests.LConfig `yaml:"manifests,omitempty,flow"`
+
+	Minikube minikube.LConfig `yaml:"minikube,omitempty,flow"`
+
+	Profiles map[string]Profile `yaml:"profiles,omitempty"`
+	// Profile is the name of the applied profile.
+	Profile string `yaml:"-"`
+
+	// path of the file the LConfig is read from.
+	path string
+	// migratedFrom is the version of the file the LConfig is migrated from on load.
+	migratedFrom string
 }
 
 // NewConfig is a constructor for Config.
@@ -55,57 +76,114 @@ func NewLConfigFromFile(file *os.File) (LConfig, error) {
 		return LConfig{}, errors.New("file is required")
 	}
 
-	lc := LConfig{file: file}
-	if err := yaml.NewDecoder(file).Decode(&lc); err != nil {
+	data, fver, err := migrateFile(file, lconfigMigrations)
+	if err != nil {
+		return LConfig{}, err
+	}
+
+	lc := LConfig{path: file.Name(), migratedFrom: fver}
+	if err := yaml.Unmarshal(data, &lc); err != nil {
 		return LConfig{}, err
 	}
 
 	return lc, nil
 }
 
+// NewLConfigFromPath reads LConfig from the .yaml file by the path.
+// File is closed right after, so it could be replaced by Save.
+func NewLConfigFromPath(path string) (LConfig, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return LConfig{}, err
+	}
+	defer f.Close()
+
+	return NewLConfigFromFile(f)
+}
+
 // Validate the Config.
+// Returns Problems with all the found issues.
 func (c *LConfig) Validate() error {
-	switch {
-	case c.Version == "":
-		return errors.New("version is required")
-	case c.Name == "":
-		return errors.New("name is required")
+	var ps Problems
+	if c.Version == "" {
+		ps = append(ps, Problem{Path: "version", Msg: "version is required"})
+	}
+	if c.Name == "" {
+		ps = append(ps, Problem{Path: "name", Msg: "name is required"})
 	}
 
 	if err := c.Docker.Validate(); err != nil {
-		return fmt.Errorf("docker configuration is invalid: %s", err)
+		ps = append(ps, Problem{Path: "docker", Msg: fmt.Sprintf("docker configuration is invalid: %s", err)})
 	}
 
-	if err := c.Helm.Validate(); err != nil {
-		return fmt.Errorf("helm configuration is invalid: %s", err)
+	if err := c.Deploy.Validate(); err != nil {
+		ps = append(ps, Problem{Path: "deploy", Msg: fmt.Sprintf("deploy configuration is invalid: %s", err)})
 	}
 
-	return nil
+	switch c.DeployType() {
+	case DeployHelm:
+		if err := c.Helm.Validate(); err != nil {
+			ps = append(ps, Problem{Path: "helm", Msg: fmt.Sprintf("helm configuration is invalid: %s", err)})
+		}
+	case DeployKustomize:
+		kc := c.Kustomize
+		// image defaults to the name of the project.
+		if kc.Image == "" {
+			kc.Image = c.Name
+		}
+		if err := kc.Validate(); err != nil {
+			ps = append(ps, Problem{Path: "kustomize", Msg: fmt.Sprintf("kustomize configuration is invalid: %s", err)})
+		}
+	case DeployManifests:
+		if err := c.Manifests.Validate(); err != nil {
+			ps = append(ps, Problem{Path: "manifests", Msg: fmt.Sprintf("manifests configuration is invalid: %s", err)})
+		}
+	}
+
+	for i, pf := range c.PortForward {
+		if err := pf.Validate(); err != nil {
+			ps = append(ps, Problem{Path: "port_forward", Msg: fmt.Sprintf("port forward #%d is invalid: %s", i+1, err)})
+		}
+	}
+
+	return ps.Err()
 }
 
 // Save the LConfig to the provided path as a .yaml file.
-// If file is not provided but Config was created from file -
+// If path is not provided but LConfig was read from file -
 // config would be written to the file.
-func (c *LConfig) Save(file *os.File) error {
-	if file == nil && c.file != nil {
-		file = c.file
+// File is replaced atomically, so it shouldn't be kept open.
+// File migrated on load is backed up before it's replaced.
+func (c *LConfig) Save(path string) error {
+	if path == "" {
+		path = c.path
+	}
+	if path == "" {
+		return errors.New("local configuration file is not set")
 	}
 
 	if err := c.Validate(); err != nil {
 		return fmt.Errorf("local configuration is not valid: %s", err)
 	}
 
-	if err := file.Truncate(0); err != nil {
+	data, err := yaml.Marshal(c)
+	if err != nil {
 		return err
 	}
-	if _, err := file.Seek(0, 0); err != nil {
-		return err
+
+	if c.migratedFrom != "" && path == c.path {
+		if err := backupMigrated(path, c.migratedFrom); err != nil {
+			return err
+		}
+		c.migratedFrom = ""
 	}
-	if err := yaml.NewEncoder(file).Encode(c); err != nil {
+
+	if err := writeFileAtomic(path, data, 0644); err != nil {
 		return err
 	}
 
-	return file.Sync()
+	c.path = path
+	return nil
 }
 
 // CheckGlobalDeps if all the required services are installed.
@@ -114,23 +192,31 @@ func (lc *LConfig) CheckGlobalDeps() error {
 		return fmt.Errorf("kubectl: %s", err)
 	}
 
-	if _, err := helm.CheckInstalled(lc.Helm.BinaryPath); err != nil {
-		return fmt.Errorf("helm: %s", err)
-	}
-
-	if _, err := minikube.CheckInstalled(); err != nil {
-		return fmt.Errorf("minikube: %s", err)
+	if lc.DeployType() == DeployHelm {
+		if _, err := helm.CheckInstalled(lc.Helm.BinaryPath); err != nil {
+			return fmt.Errorf("helm: %s", err)
+		}
 	}
 
 	return nil
 }
 
-func (lc *LConfig) CheckGlobalDepsReady() error {
+// CheckGlobalDepsReady checks the global dependencies and, if the kubernetes context
+// is the one of the minikube profile, that minikube is installed and running.
+func (lc *LConfig) CheckGlobalDepsReady(k8sCtx string) error {
 	if err := lc.CheckGlobalDeps(); err != nil {
 		return fmt.Errorf("global dependency returned error: %s", err)
 	}
 
-	if err := minikube.CheckRunning(); err != nil {
+	if !lc.Minikube.IsContext(k8sCtx) {
+		return nil
+	}
+
+	if _, err := minikube.CheckInstalled(); err != nil {
+		return fmt.Errorf("minikube: %s", err)
+	}
+
+	if err := minikube.CheckRunning(lc.Minikube.Profile); err != nil {
 		return fmt.Errorf("minikube: %s", err)
 	}
 
@@ -138,10 +224,12 @@ func (lc *LConfig) CheckGlobalDepsReady() error {
 }
 
 // Dep represents Dependency of the Project.
+// Image is the docker image of the service dependency.
 type Dep struct {
-	Name    string  `yaml:"name"`
-	Type    DepType `yaml:"type"`
-	Prepare func() error
+	Name    string       `yaml:"name"`
+	Type    DepType      `yaml:"type"`
+	Image   string       `yaml:"image,omitempty"`
+	Prepare func() error `yaml:"-"`
 }
 
 // Validate the dep.
@@ -0,0 +1,81 @@
+package mannequin
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+const (
+	lockExt = ".lock"
+	// lockTimeout is how long the lock held by another mnqnctl is waited for.
+	lockTimeout = 10 * time.Second
+	lockRetry   = 100 * time.Millisecond
+)
+
+// lockFile takes the advisory exclusive lock of the file at the path.
+// Lock is held on the separate path.lock file, so the file itself could be replaced.
+// Returns the func that releases the lock.
+func lockFile(path string) (func(), error) {
+	deadline := time.Now().Add(lockTimeout)
+	for {
+		unlock, locked, err := tryLock(path + lockExt)
+		if err != nil {
+			return nil, fmt.Errorf("couldn't lock %s: %s", path, err)
+		}
+		if locked {
+			return unlock, nil
+		}
+
+		if time.Now().After(deadline) {
+			return nil, fmt.Errorf("%s is locked by another mnqnctl (lock file %s)", path, path+lockExt)
+		}
+		time.Sleep(lockRetry)
+	}
+}
+
+// writeFileAtomic writes the data to the temporary file next to the path
+// and replaces the file with it, so the file is never left partially written.
+// Mode of the existing file is kept.
+func writeFileAtomic(path string, data []byte, perm os.FileMode) error {
+	if info, err := os.Stat(path); err == nil {
+		perm = info.Mode().Perm()
+	}
+
+	f, err := ioutil.TempFile(filepath.Dir(path), "."+filepath.Base(path)+".tmp")
+	if err != nil {
+		return err
+	}
+	tmp := f.Name()
+
+	if err := writeAndClose(f, data, perm); err != nil {
+		os.Remove(tmp) // nolint: errcheck
+		return err
+	}
+
+	if err := os.Rename(tmp, path); err != nil {
+		os.Remove(tmp) // nolint: errcheck
+		return err
+	}
+
+	return nil
+}
+
+func writeAndClose(f *os.File, data []byte, perm os.FileMode) error {
+	if _, err := f.Write(data); err != nil {
+		f.Close() // nolint: errcheck
+		return err
+	}
+	if err := f.Chmod(perm); err != nil {
+		f.Close() // nolint: errcheck
+		return err
+	}
+	if err := f.Sync(); err != nil {
+		f.Close() // nolint: errcheck
+		return err
+	}
+
+	return f.Close()
+}
@@ -0,0 +1,31 @@
+//go:build !windows
+// +build !windows
+
+package mannequin
+
+import (
+	"os"
+	"syscall"
+)
+
+// tryLock takes the flock of the lock file without blocking.
+// Lock is released by the OS if the process dies.
+func tryLock(path string) (func(), bool, error) {
+	f, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE, 0600)
+	if err != nil {
+		return nil, false, err
+	}
+
+	if err := syscall.Flock(int(f.Fd()), syscall.LOCK_EX|syscall.LOCK_NB); err != nil {
+		f.Close() // nolint: errcheck
+		if err == syscall.EWOULDBLOCK {
+			return nil, false, nil
+		}
+		return nil, false, err
+	}
+
+	return func() {
+		syscall.Flock(int(f.Fd()), syscall.LOCK_UN) // nolint: errcheck
+		f.Close()                                   // nolint: errcheck
+	}, true, nil
+}
@@ -0,0 +1,25 @@
+//go:build windows
+// +build windows
+
+package mannequin
+
+import (
+	"os"
+)
+
+// tryLock creates the lock file exclusively, the file is removed on release.
+// Lock file is left behind if the process dies and has to be removed manually.
+func tryLock(path string) (func(), bool, error) {
+	f, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE|os.O_EXCL, 0600)
+	if os.IsExist(err) {
+		return nil, false, nil
+	}
+	if err != nil {
+		return nil, false, err
+	}
+
+	return func() {
+		f.Close()       // nolint: errcheck
+		os.Remove(path) // nolint: errcheck
+	}, true, nil
+}
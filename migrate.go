@@ -0,0 +1,183 @@
+package mannequin
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v2"
+)
+
+// oldestVer is assumed for the files that have no version.
+const oldestVer = "v0.0.1"
+
+// migration upgrades the raw document of the From version to the To version.
+type migration struct {
+	From string
+	To   string
+	Up   func(doc yaml.MapSlice) (yaml.MapSlice, error)
+}
+
+// configMigrations of the global configuration in the order they are applied.
+var configMigrations = []migration{
+	// new keys are optional, only the version is bumped.
+	{From: "v0.0.1", To: "v0.1.0"},
+}
+
+// lconfigMigrations of the local configuration in the order they are applied.
+var lconfigMigrations = []migration{
+	{From: "v0.0.1", To: "v0.1.0", Up: func(doc yaml.MapSlice) (yaml.MapSlice, error) {
+		// single helm values path becomes the list of values files.
+		h, ok := mapValue(doc, "helm").(yaml.MapSlice)
+		if !ok {
+			return doc, nil
+		}
+		if p, ok := mapValue(h, "values").(string); ok {
+			setMapValue(h, "values", []interface{}{p})
+		}
+
+		return doc, nil
+	}},
+}
+
+// migrateFile upgrades the contents of the file to the current version using provided migrations.
+// File itself is not changed, the upgrade is persisted once the configuration is saved.
+// Returns the (possibly migrated) contents of the file and the version it's migrated from
+// (empty if the file is up to date).
+// Files written by the newer version are rejected.
+func migrateFile(file *os.File, ms []migration) ([]byte, string, error) {
+	data, err := ioutil.ReadAll(file)
+	if err != nil {
+		return nil, "", err
+	}
+
+	var doc yaml.MapSlice
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return nil, "", err
+	}
+	if len(doc) == 0 {
+		return data, "", nil
+	}
+
+	fver, _ := mapValue(doc, "version").(string)
+	if fver == "" {
+		fver = oldestVer
+	}
+
+	cmp, err := compareVers(fver, ver)
+	switch {
+	case err != nil:
+		return nil, "", fmt.Errorf("%s: %s", file.Name(), err)
+	case cmp > 0:
+		return nil, "", fmt.Errorf("%s is written by newer mnqnctl (%s, current is %s): upgrade mnqnctl", file.Name(), fver, ver)
+	case cmp == 0:
+		return data, "", nil
+	}
+
+	cur := fver
+	for _, m := range ms {
+		if c, err := compareVers(cur, m.To); err != nil || c >= 0 {
+			continue
+		}
+
+		if m.Up != nil {
+			if doc, err = m.Up(doc); err != nil {
+				return nil, "", fmt.Errorf("couldn't migrate %s from %s to %s: %s", file.Name(), m.From, m.To, err)
+			}
+		}
+		cur = m.To
+	}
+	doc = setMapValue(doc, "version", ver)
+
+	res, err := yaml.Marshal(doc)
+	if err != nil {
+		return nil, "", err
+	}
+
+	return res, fver, nil
+}
+
+// backupMigrated copies the file that is about to be replaced with the migrated version
+// to <file>.<version>.bak.
+func backupMigrated(path, fver string) error {
+	data, err := ioutil.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	backup := path + "." + fver + ".bak"
+	if err := writeFileAtomic(backup, data, 0600); err != nil {
+		return fmt.Errorf("couldn't back up %s: %s", path, err)
+	}
+
+	return nil
+}
+
+// compareVers returns -1, 0 or 1 if a is lower, equal or greater than b.
+// Versions are expected in vMAJOR.MINOR.PATCH format.
+func compareVers(a, b string) (int, error) {
+	pa, err := parseVer(a)
+	if err != nil {
+		return 0, err
+	}
+	pb, err := parseVer(b)
+	if err != nil {
+		return 0, err
+	}
+
+	for i := range pa {
+		switch {
+		case pa[i] < pb[i]:
+			return -1, nil
+		case pa[i] > pb[i]:
+			return 1, nil
+		}
+	}
+
+	return 0, nil
+}
+
+func parseVer(v string) ([3]int, error) {
+	var res [3]int
+
+	parts := strings.Split(strings.TrimPrefix(v, "v"), ".")
+	if len(parts) != 3 {
+		return res, fmt.Errorf("version \"%s\" is malformed", v)
+	}
+
+	for i, p := range parts {
+		n, err := strconv.Atoi(p)
+		if err != nil {
+			return res, fmt.Errorf("version \"%s\" is malformed", v)
+		}
+		res[i] = n
+	}
+
+	return res, nil
+}
+
+func mapValue(ms yaml.MapSlice, key string) interface{} {
+	for _, i := range ms {
+		if i.Key == key {
+			return i.Value
+		}
+	}
+
+	return nil
+}
+
+func setMapValue(ms yaml.MapSlice, key string, val interface{}) yaml.MapSlice {
+	for i := range ms {
+		if ms[i].Key == key {
+			ms[i].Value = val
+			return ms
+		}
+	}
+
+	return append(ms, yaml.MapItem{Key: key, Value: val})
+}
@@ -0,0 +1,234 @@
+package mannequin
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"gopkg.in/yaml.v2"
+)
+
+func TestMigrateFile(t *testing.T) {
+	dir, err := ioutil.TempDir("", "migrate")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	tests := []struct {
+		name string
+		doc  string
+		ms   []migration
+		want map[string]interface{}
+		from string
+		err  string
+	}{
+		{
+			name: "empty file",
+			doc:  "",
+			ms:   lconfigMigrations,
+		},
+		{
+			name: "up to date",
+			doc:  "version: " + ver + "\nhelm:\n  values: values.yaml\n",
+			ms:   lconfigMigrations,
+			want: map[string]interface{}{
+				"version": ver,
+				"helm":    map[interface{}]interface{}{"values": "values.yaml"},
+			},
+		},
+		{
+			name: "no version",
+			doc:  "helm:\n  values: values.yaml\n",
+			ms:   lconfigMigrations,
+			want: map[string]interface{}{
+				"version": ver,
+				"helm":    map[interface{}]interface{}{"values": []interface{}{"values.yaml"}},
+			},
+			from: oldestVer,
+		},
+		{
+			name: "values list is kept",
+			doc:  "version: v0.0.1\nhelm:\n  values:\n    - values.yaml\n",
+			ms:   lconfigMigrations,
+			want: map[string]interface{}{
+				"version": ver,
+				"helm":    map[interface{}]interface{}{"values": []interface{}{"values.yaml"}},
+			},
+			from: "v0.0.1",
+		},
+		{
+			name: "global configuration",
+			doc:  "version: v0.0.1\nprojects: []\n",
+			ms:   configMigrations,
+			want: map[string]interface{}{
+				"version":  ver,
+				"projects": []interface{}{},
+			},
+			from: "v0.0.1",
+		},
+		{
+			name: "newer version",
+			doc:  "version: v99.0.0\n",
+			ms:   lconfigMigrations,
+			err:  "written by newer mnqnctl",
+		},
+		{
+			name: "malformed version",
+			doc:  "version: latest\n",
+			ms:   lconfigMigrations,
+			err:  "version \"latest\" is malformed",
+		},
+	}
+
+	for n, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(dir, strings.Repeat("f", n+1)+".yaml")
+			if err := ioutil.WriteFile(path, []byte(tt.doc), 0600); err != nil {
+				t.Fatal(err)
+			}
+			f, err := os.Open(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer f.Close()
+
+			data, from, err := migrateFile(f, tt.ms)
+			if tt.err != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.err) {
+					t.Fatalf("expected error containing \"%s\", got %v", tt.err, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if from != tt.from {
+				t.Errorf("expected to be migrated from \"%s\", got \"%s\"", tt.from, from)
+			}
+
+			var got map[string]interface{}
+			if err := yaml.Unmarshal(data, &got); err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("expected %v, got %v", tt.want, got)
+			}
+
+			// file itself is not changed.
+			orig, err := ioutil.ReadFile(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(orig) != tt.doc {
+				t.Errorf("file is changed: %s", orig)
+			}
+		})
+	}
+}
+
+func TestCompareVers(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want int
+		err  bool
+	}{
+		{a: "v0.1.0", b: "v0.1.0", want: 0},
+		{a: "v0.0.1", b: "v0.1.0", want: -1},
+		{a: "v0.10.0", b: "v0.9.0", want: 1},
+		{a: "1.0.0", b: "v0.9.9", want: 1},
+		{a: "v0.1", b: "v0.1.0", err: true},
+		{a: "v0.1.0", b: "v0.x.0", err: true},
+	}
+
+	for _, tt := range tests {
+		got, err := compareVers(tt.a, tt.b)
+		if (err != nil) != tt.err {
+			t.Errorf("%s vs %s: unexpected error %v", tt.a, tt.b, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("%s vs %s: expected %d, got %d", tt.a, tt.b, tt.want, got)
+		}
+	}
+}
+
+func TestBackupMigrated(t *testing.T) {
+	dir, err := ioutil.TempDir("", "migrate")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "config.yaml")
+	if err := backupMigrated(path, "v0.0.1"); err != nil {
+		t.Fatalf("missing file is expected to be skipped, got %s", err)
+	}
+
+	if err := ioutil.WriteFile(path, []byte("projects: []\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if err := backupMigrated(path, "v0.0.1"); err != nil {
+		t.Fatal(err)
+	}
+
+	data, err := ioutil.ReadFile(path + ".v0.0.1.bak")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != "projects: []\n" {
+		t.Errorf("unexpected backup: %s", data)
+	}
+}
+
+func TestLConfigSaveMigrated(t *testing.T) {
+	dir, err := ioutil.TempDir("", "migrate")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, ".mnqn.yaml")
+	orig := "version: v0.0.1\nname: app\nhelm:\n  chart: chart\n  release_name: app\n  values: values.yaml\n"
+	if err := ioutil.WriteFile(path, []byte(orig), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	lc, err := NewLConfigFromPath(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := lc.Save(""); err != nil {
+		t.Fatal(err)
+	}
+
+	backup, err := ioutil.ReadFile(path + ".v0.0.1.bak")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(backup) != orig {
+		t.Errorf("expected the original file to be backed up, got %s", backup)
+	}
+
+	saved, err := NewLConfigFromPath(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if saved.Version != ver || saved.migratedFrom != "" {
+		t.Errorf("expected the file to be migrated to %s, got %s (from %s)", ver, saved.Version, saved.migratedFrom)
+	}
+
+	// up to date file is not backed up again.
+	if err := os.Remove(path + ".v0.0.1.bak"); err != nil {
+		t.Fatal(err)
+	}
+	if err := saved.Save(""); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(path + ".v0.0.1.bak"); !os.IsNotExist(err) {
+		t.Errorf("expected no backup of the up to date file, got %v", err)
+	}
+}
@@ -1,23 +1,27 @@
 package mannequin
 
 import (
+	"bufio"
 	"errors"
 	"fmt"
 	"io"
+	"os"
+	"sort"
 	"strings"
 )
 
-const (
-	ver           = "v0.0.1"
-	defaultK8SCtx = "minikube"
-)
+const ver = "v0.1.0"
 
 // Mnqn represents working application data layer.
 type Mnqn struct {
 	K8SContext string
+	Namespace  string
+	ConfigPath string
+	Verbose    bool
 	Version    string
 	Config     *Config
 	LocalVars  LocalVars
+	r          *bufio.Reader
 	w          io.Writer
 }
 
@@ -39,19 +43,43 @@ func New(w io.Writer, cfg *Config) (Mnqn, error) {
 	}
 
 	return Mnqn{
-		Version:    ver,
-		Config:     cfg,
-		LocalVars:  map[string]string{},
-		K8SContext: defaultK8SCtx,
-		w:          w,
+		Version:   ver,
+		Config:    cfg,
+		LocalVars: map[string]string{},
+		r:         bufio.NewReader(os.Stdin),
+		w:         w,
 	}, nil
 }
 
+// Read impl.
+func (m Mnqn) Read(p []byte) (n int, err error) {
+	return m.r.Read(p)
+}
+
+// ReadLine reads the line of the input without the surrounding spaces.
+func (m Mnqn) ReadLine() (string, error) {
+	text, err := m.r.ReadString('\n')
+	if err == io.EOF && text != "" {
+		err = nil
+	}
+
+	return strings.TrimSpace(text), err
+}
+
 // Write impl.
 func (m Mnqn) Write(p []byte) (n int, err error) {
 	return m.w.Write(p)
 }
 
+// Debugf prints the formatted output only if Verbose is set.
+func (m Mnqn) Debugf(format string, a ...interface{}) {
+	if !m.Verbose {
+		return
+	}
+
+	fmt.Fprintf(m.w, format, a...)
+}
+
 type LocalVars map[string]string
 
 // RegisterVar to the execution context.
@@ -89,18 +117,32 @@ func (lv *LocalVars) Var(name string) (string, error) {
 	return val, nil
 }
 
-// Replace the variable placeholder with it's value (if registered).
-// Expected variable template is `$VARIABLE_NAME` where VARIABLE_NAME is the name of the
-// variable with which it's registered.
+// Replace the variable placeholders with their values (if registered).
+// Expected variable template is `$VARIABLE_NAME` or `${VARIABLE_NAME}` where VARIABLE_NAME is the name of the
+// variable with which it's registered. Longest names are matched first, so `$NAME_SUFFIX` is never
+// replaced as `$NAME` followed by `_SUFFIX`. Replaced values are not replaced again.
 // Returns provided string if there is nothing to replace.
 func (lv *LocalVars) Replace(value string) string {
-	if lv == nil {
+	if lv == nil || len(*lv) == 0 {
 		return value
 	}
 
-	for k, v := range *lv {
-		value = strings.ReplaceAll(value, "$"+k, v)
+	names := make([]string, 0, len(*lv))
+	for k := range *lv {
+		names = append(names, k)
+	}
+	sort.Slice(names, func(i, j int) bool {
+		if len(names[i]) != len(names[j]) {
+			return len(names[i]) > len(names[j])
+		}
+		return names[i] < names[j]
+	})
+
+	// replacer matches the placeholders in the order they are provided.
+	pairs := make([]string, 0, len(names)*4)
+	for _, k := range names {
+		pairs = append(pairs, "${"+k+"}", (*lv)[k], "$"+k, (*lv)[k])
 	}
 
-	return value
+	return strings.NewReplacer(pairs...).Replace(value)
 }
@@ -0,0 +1,40 @@
+package mannequin
+
+import "testing"
+
+func TestLocalVarsReplace(t *testing.T) {
+	lv := LocalVars{
+		"NAME":        "app",
+		"NAME_SUFFIX": "suffix",
+		"DOLLAR":      "$NAME",
+	}
+
+	tests := []struct {
+		value string
+		want  string
+	}{
+		{value: "", want: ""},
+		{value: "nothing", want: "nothing"},
+		{value: "$NAME", want: "app"},
+		{value: "$NAME_SUFFIX", want: "suffix"},
+		{value: "$NAME-$NAME_SUFFIX", want: "app-suffix"},
+		{value: "${NAME}_SUFFIX", want: "app_SUFFIX"},
+		{value: "$NAME_OTHER", want: "app_OTHER"},
+		{value: "$UNKNOWN", want: "$UNKNOWN"},
+		{value: "$DOLLAR", want: "$NAME"},
+	}
+
+	// map order is random, so the replacement is repeated.
+	for n := 0; n < 20; n++ {
+		for _, tt := range tests {
+			if got := lv.Replace(tt.value); got != tt.want {
+				t.Fatalf("%s: expected \"%s\", got \"%s\"", tt.value, tt.want, got)
+			}
+		}
+	}
+
+	var nilVars *LocalVars
+	if got := nilVars.Replace("$NAME"); got != "$NAME" {
+		t.Errorf("expected nil variables to keep the value, got \"%s\"", got)
+	}
+}
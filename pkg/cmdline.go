@@ -0,0 +1,19 @@
+package pkg
+
+import (
+	"strconv"
+	"strings"
+)
+
+// Cmdline returns the command arguments joined the way they could be copied to the shell.
+func Cmdline(args []string) string {
+	res := make([]string, len(args))
+	for i, a := range args {
+		if a == "" || strings.ContainsAny(a, " \t\n\"'$`\\|&;<>(){}*?[]#~") {
+			a = strconv.Quote(a)
+		}
+		res[i] = a
+	}
+
+	return strings.Join(res, " ")
+}
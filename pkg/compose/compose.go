@@ -0,0 +1,258 @@
+package compose
+
+import (
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"sort"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v2"
+)
+
+// DefaultFilePaths of the compose file in the order they are looked up.
+var DefaultFilePaths = []string{"docker-compose.yml", "docker-compose.yaml"}
+
+// wellKnownImages are the images of the services the project could depend on.
+var wellKnownImages = []string{
+	"mysql", "mariadb", "postgres", "mongo", "redis", "memcached",
+	"rabbitmq", "elasticsearch", "kafka", "zookeeper", "nats", "minio", "localstack",
+}
+
+// File is the parsed compose file.
+// Only the keys that are needed to configure the project are parsed.
+type File struct {
+	Services map[string]Service `yaml:"services"`
+}
+
+// Service of the compose file.
+type Service struct {
+	Image       string      `yaml:"image"`
+	Build       *Build      `yaml:"build"`
+	Environment Environment `yaml:"environment"`
+	Ports       Ports       `yaml:"ports"`
+}
+
+// Build section of the service.
+type Build struct {
+	Context    string `yaml:"context"`
+	Dockerfile string `yaml:"dockerfile"`
+}
+
+// UnmarshalYAML impl.
+// Build could be set as a path to the context.
+func (b *Build) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	var ctx string
+	if err := unmarshal(&ctx); err == nil {
+		*b = Build{Context: ctx}
+		return nil
+	}
+
+	type plain Build
+	return unmarshal((*plain)(b))
+}
+
+// Environment of the service.
+type Environment map[string]string
+
+// UnmarshalYAML impl.
+// Environment could be set either as a map or as a list of NAME=VALUE.
+func (e *Environment) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	res := Environment{}
+
+	var list []string
+	if err := unmarshal(&list); err == nil {
+		for _, kv := range list {
+			parts := strings.SplitN(kv, "=", 2)
+			if len(parts) == 1 {
+				// value is taken from the environment.
+				parts = append(parts, "${"+parts[0]+"}")
+			}
+			res[parts[0]] = parts[1]
+		}
+
+		*e = res
+		return nil
+	}
+
+	var m map[string]interface{}
+	if err := unmarshal(&m); err != nil {
+		return err
+	}
+	for k, v := range m {
+		if v == nil {
+			v = "${" + k + "}"
+		}
+		res[k] = fmt.Sprint(v)
+	}
+
+	*e = res
+	return nil
+}
+
+// Port mapping of the service.
+// Host is 0 if the port is not published.
+type Port struct {
+	Host      int
+	Container int
+	// Unresolved is the port as it's set in the file if it references variables (e.g. ${PORT}:80).
+	Unresolved string
+}
+
+// Ports of the service.
+type Ports []Port
+
+// UnmarshalYAML impl.
+// Short ("8080:80", "127.0.0.1:8080:80/tcp", 80) and long
+// (target, published) syntaxes are supported.
+func (ps *Ports) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	var raw []interface{}
+	if err := unmarshal(&raw); err != nil {
+		return err
+	}
+
+	res := Ports{}
+	for _, r := range raw {
+		var (
+			p   Port
+			err error
+		)
+
+		switch v := r.(type) {
+		case int:
+			p.Container = v
+		case string:
+			if strings.Contains(v, "$") {
+				p.Unresolved = v
+				break
+			}
+			p, err = parsePort(v)
+		case map[interface{}]interface{}:
+			if strings.Contains(fmt.Sprint(v["target"], v["published"]), "$") {
+				p.Unresolved = fmt.Sprintf("published: %v, target: %v", v["published"], v["target"])
+				break
+			}
+			p.Container, err = toPort(v["target"])
+			if err == nil && v["published"] != nil {
+				p.Host, err = toPort(v["published"])
+			}
+		default:
+			err = fmt.Errorf("unexpected port %v", r)
+		}
+		if err != nil {
+			return err
+		}
+
+		res = append(res, p)
+	}
+
+	*ps = res
+	return nil
+}
+
+func parsePort(s string) (Port, error) {
+	s = strings.SplitN(s, "/", 2)[0]
+	parts := strings.Split(s, ":")
+
+	var (
+		p   Port
+		err error
+	)
+	if p.Container, err = toPort(parts[len(parts)-1]); err != nil {
+		return Port{}, err
+	}
+	if len(parts) > 1 {
+		if p.Host, err = toPort(parts[len(parts)-2]); err != nil {
+			return Port{}, err
+		}
+	}
+
+	return p, nil
+}
+
+func toPort(v interface{}) (int, error) {
+	s := fmt.Sprint(v)
+	// ranges are not supported, first port is used.
+	s = strings.SplitN(s, "-", 2)[0]
+
+	p, err := strconv.Atoi(s)
+	if err != nil || p <= 0 || p > 65535 {
+		return 0, fmt.Errorf("port \"%v\" is not valid", v)
+	}
+
+	return p, nil
+}
+
+// Read the compose file.
+func Read(path string) (File, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return File{}, err
+	}
+
+	var f File
+	if err := yaml.Unmarshal(data, &f); err != nil {
+		return File{}, fmt.Errorf("couldn't parse %s: %s", path, err)
+	}
+	if len(f.Services) == 0 {
+		return File{}, fmt.Errorf("no services are found in %s", path)
+	}
+
+	return f, nil
+}
+
+// ServiceNames sorted by name.
+func (f File) ServiceNames() []string {
+	res := make([]string, 0, len(f.Services))
+	for n := range f.Services {
+		res = append(res, n)
+	}
+	sort.Strings(res)
+
+	return res
+}
+
+// Built returns the name of the service that is built from the sources.
+// If there are several ones - the one named as the project is preferred.
+func (f File) Built(project string) (string, error) {
+	var built []string
+	for _, n := range f.ServiceNames() {
+		if f.Services[n].Build != nil {
+			built = append(built, n)
+		}
+	}
+
+	switch len(built) {
+	case 0:
+		return "", errors.New("no service with the build section is found")
+	case 1:
+		return built[0], nil
+	}
+
+	for _, n := range built {
+		if n == project {
+			return n, nil
+		}
+	}
+
+	return built[0], nil
+}
+
+// WellKnown reports if the image is one of the well known services (e.g. databases).
+func WellKnown(image string) bool {
+	name := image
+	if i := strings.LastIndex(name, "/"); i != -1 {
+		name = name[i+1:]
+	}
+	name = strings.SplitN(name, ":", 2)[0]
+	name = strings.SplitN(name, "@", 2)[0]
+
+	for _, n := range wellKnownImages {
+		if name == n {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,29 @@
+package pkg
+
+import (
+	"io"
+	"time"
+)
+
+// Deployer deploys the project to the kubernetes cluster.
+// Every deployment backend (e.g. helm, kustomize) implements it.
+type Deployer interface {
+	// Check if the tools required by the backend are installed.
+	Check() error
+	// Lint the manifests with the same variables that are used for the deployment.
+	Lint(w io.Writer, vars VarStorer) error
+	// Plan prints what would be deployed without changing anything.
+	Plan(w io.Writer, vars VarStorer) error
+	// Deploy the project.
+	Deploy(w io.Writer, vars VarStorer) error
+	// Down removes everything that is deployed by the project.
+	Down(w io.Writer, vars VarStorer) error
+}
+
+// Target of the deployment.
+// Timeout is used only if Wait is set.
+type Target struct {
+	KubeContext string
+	Wait        bool
+	Timeout     time.Duration
+}
@@ -40,6 +40,9 @@ func BuildImage(w io.Writer, vars pkg.VarStorer, lc LConfig) error {
 	if err := lc.Validate(); err != nil {
 		return err
 	}
+	if lc.File == "" {
+		lc.File = DefaultFilePath
+	}
 
 	// check dockerfile.
 	_, err := os.Stat(lc.File)
@@ -50,28 +53,16 @@ func BuildImage(w io.Writer, vars pkg.VarStorer, lc LConfig) error {
 		return fmt.Errorf("could not check if dockerfile exists: %s", err)
 	}
 
-	// generate image tag.
-	tag, err := lc.ImageTag()
+	args, err := BuildArgs(vars, lc)
 	if err != nil {
 		return err
 	}
 
-	// set vars.
-	if err := vars.Register(VarDockerImageTag, tag); err != nil {
-		return err
-	}
-	if err := vars.Register(VarDockerImageVersion, lc.Version); err != nil {
-		return err
-	}
-	if err := vars.Register(VarDockerImageName, lc.ImageName); err != nil {
-		return err
-	}
-
 	fmt.Fprintln(w, "Building:")
 	fmt.Fprintln(w, "----------------------------------------------------")
 
 	// run the command.
-	cmd := exec.Command("docker", "build", "-t", tag, "-f", lc.File, ".")
+	cmd := exec.Command(args[0], args[1:]...)
 	cmd.Stderr = w
 	cmd.Stdout = w
 
@@ -93,3 +84,86 @@ func BuildImage(w io.Writer, vars pkg.VarStorer, lc LConfig) error {
 
 	return nil
 }
+
+// BuildArgs registers the docker vars and returns the command that builds the image.
+func BuildArgs(vars pkg.VarStorer, lc LConfig) ([]string, error) {
+	if err := RegisterVars(vars, lc); err != nil {
+		return nil, err
+	}
+
+	tag, err := lc.ImageTag()
+	if err != nil {
+		return nil, err
+	}
+
+	file := lc.File
+	if file == "" {
+		file = DefaultFilePath
+	}
+
+	return []string{"docker", "build", "-t", tag, "-f", file, "."}, nil
+}
+
+// RegisterVars sets the docker vars of the image that is going to be built.
+func RegisterVars(vars pkg.VarStorer, lc LConfig) error {
+	if vars == nil {
+		return errors.New("variable store is required")
+	}
+
+	// generate image tag.
+	tag, err := lc.ImageTag()
+	if err != nil {
+		return err
+	}
+
+	// set vars.
+	if err := vars.Register(VarDockerImageTag, tag); err != nil {
+		return err
+	}
+	if err := vars.Register(VarDockerImageVersion, lc.Version); err != nil {
+		return err
+	}
+
+	return vars.Register(VarDockerImageName, lc.ImageName)
+}
+
+// Images returns the local images of the repositories matching the pattern
+// (e.g. mnqn.local/*) in repository:tag format.
+func Images(pattern string) ([]string, error) {
+	out, err := exec.Command("docker", "images", "--filter", "reference="+pattern,
+		"--format", "{{.Repository}}:{{.Tag}}").Output()
+	if err != nil {
+		ee, ok := err.(*exec.ExitError)
+		if !ok {
+			return nil, errors.New("docker is not installed")
+		}
+
+		return nil, fmt.Errorf("error code %d: %s", ee.ExitCode(), strings.TrimSpace(string(ee.Stderr)))
+	}
+
+	var res []string
+	for _, l := range strings.Split(string(out), "\n") {
+		if l = strings.TrimSpace(l); l != "" {
+			res = append(res, l)
+		}
+	}
+
+	return res, nil
+}
+
+// RemoveImages removes the local images.
+func RemoveImages(w io.Writer, images ...string) error {
+	if len(images) == 0 {
+		return nil
+	}
+
+	cmd := exec.Command("docker", append([]string{"rmi"}, images...)...)
+	cmd.Stdout = w
+	cmd.Stderr = w
+
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("couldn't remove images: %s", err)
+	}
+
+	return nil
+}
@@ -3,9 +3,12 @@ package docker
 import (
 	"errors"
 	"fmt"
+	"regexp"
 	"time"
 )
 
+var validImageName = regexp.MustCompile(`^[a-z0-9]+([._-]+[a-z0-9]+)*(:[0-9]+)?(/[a-z0-9]+([._-]+[a-z0-9]+)*)*$`)
+
 // LConfig for Docker.
 type LConfig struct {
 	ImageName string `yaml:"image_name,flow"`
@@ -15,6 +18,10 @@ type LConfig struct {
 
 // Validate the LConfig.
 func (lc *LConfig) Validate() error {
+	if lc.ImageName != "" && !validImageName.MatchString(lc.ImageName) {
+		return fmt.Errorf("image name \"%s\" is not valid: lowercase letters, digits, separators (.-_/) and optional :port are allowed", lc.ImageName)
+	}
+
 	return nil
 }
 
@@ -52,3 +59,14 @@ func (lc *LConfig) GenerateImageName(name string) error {
 
 	return nil
 }
+
+// Merge the provided LConfig into the current one.
+// Only set values of the provided LConfig override the current ones.
+func (lc *LConfig) Merge(o LConfig) {
+	if o.ImageName != "" {
+		lc.ImageName = o.ImageName
+	}
+	if o.File != "" {
+		lc.File = o.File
+	}
+}
@@ -1,25 +1,37 @@
 package helm
 
 import (
+	"bytes"
 	"errors"
 	"fmt"
 	"io"
+	"io/ioutil"
+	"os"
 	"os/exec"
 	"regexp"
+	"sort"
+	"strconv"
 	"strings"
 
 	"github.com/kostkobv/mannequin/pkg"
+
+	"gopkg.in/yaml.v2"
 )
 
 var defaultBinPath = "helm"
-var checkVer = regexp.MustCompile(`SemVer:"(v\d+.\d+.\d+)"`)
 
+// checkVer matches both Helm 2 (Client: v2.16.1+gbbdfe5e) and Helm 3 (v3.2.1+gfe51cd1) short versions.
+var checkVer = regexp.MustCompile(`v(\d+)\.(\d+)\.\d+`)
+
+const ociPrefix = "oci://"
+
+// CheckInstalled returns the version of the helm client.
 func CheckInstalled(binpath string) (string, error) {
 	if binpath == "" {
 		binpath = defaultBinPath
 	}
 
-	cmd := exec.Command(binpath, "version", "--client")
+	cmd := exec.Command(binpath, "version", "--client", "--short")
 	out, err := cmd.Output()
 	if err != nil {
 		return "", err
@@ -30,57 +42,360 @@ func CheckInstalled(binpath string) (string, error) {
 		return "", errors.New("helm is not installed")
 	}
 
-	return res[1], nil
+	return res[0], nil
+}
+
+// Major version of the helm client.
+func Major(binpath string) (int, error) {
+	major, _, err := Version(binpath)
+	return major, err
+}
+
+// Version of the helm client: major and minor.
+func Version(binpath string) (int, int, error) {
+	ver, err := CheckInstalled(binpath)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	res := checkVer.FindStringSubmatch(ver)
+	major, err := strconv.Atoi(res[1])
+	if err != nil {
+		return 0, 0, err
+	}
+	minor, err := strconv.Atoi(res[2])
+	if err != nil {
+		return 0, 0, err
+	}
+
+	return major, minor, nil
+}
+
+// WarnDeprecated prints the warning if the deprecated Helm 2 is used.
+func WarnDeprecated(w io.Writer, binpath string) {
+	if major, err := Major(binpath); err == nil && major < 3 {
+		fmt.Fprintln(w, "Warning: Helm 2 is deprecated and will not be supported, please upgrade to Helm 3.")
+	}
 }
 
 func Deploy(w io.Writer, vars pkg.VarStorer, lc LConfig) error {
-	if err := lc.Validate(); err != nil {
+	args, cleanup, err := UpgradeArgs(vars, lc)
+	if err != nil {
 		return err
 	}
+	defer cleanup()
 
-	if lc.BinaryPath == "" {
-		lc.BinaryPath = defaultBinPath
+	out, err := run(lc, args)
+	if err != nil {
+		return fmt.Errorf("failed to run deployment: %s", err)
 	}
-	if lc.Namespace == "" {
-		lc.Namespace = lc.ReleaseName
+
+	fmt.Fprintln(w, "Deploying:")
+	fmt.Fprintln(w, "----------------------------------------------------")
+	fmt.Fprintf(w, "%s\n", out)
+	fmt.Fprintln(w, "----------------------------------------------------")
+	fmt.Fprintln(w, "Successfully deployed!")
+
+	return nil
+}
+
+// UpgradeArgs returns the command that deploys the release.
+// Returned func removes the temporary files and has to be called once the command is done.
+func UpgradeArgs(vars pkg.VarStorer, lc LConfig) ([]string, func(), error) {
+	if err := lc.Validate(); err != nil {
+		return nil, func() {}, err
+	}
+	lc.setDefaults()
+
+	major, minor, err := Version(lc.BinaryPath)
+	if err != nil {
+		return nil, func() {}, fmt.Errorf("couldn't detect helm version: %s", err)
+	}
+	if lc.IsOCI() && major < 3 {
+		return nil, func() {}, errors.New("OCI charts require Helm 3")
+	}
+
+	vargs, cleanup, err := valuesArgs(vars, lc)
+	if err != nil {
+		return nil, cleanup, err
 	}
 
 	args := []string{lc.BinaryPath, "upgrade", "--install", "--namespace", vars.Replace(lc.Namespace)}
-	if lc.ValuesPath != "" {
-		args = append(args, "--values", vars.Replace(lc.ValuesPath))
+	args = append(args, contextArgs(lc)...)
+	args = append(args, createNamespaceArgs(major, minor, lc)...)
+	if lc.Atomic {
+		args = append(args, "--atomic")
 	}
-	if lc.Set != nil {
-		for k, v := range lc.Set {
-			args = append(args, "--set", vars.Replace(k)+"="+vars.Replace(v))
-		}
+	if lc.Target.Wait {
+		args = append(args, "--wait")
 	}
-	if lc.Flags != nil {
-		for k, v := range lc.Flags {
-			args = append(args, vars.Replace(k))
-			if v != "" {
-				args = append(args, vars.Replace(v))
-			}
+	if lc.Target.Wait || lc.Atomic {
+		args = append(args, timeoutArgs(major, lc)...)
+	}
+	args = append(args, chartVersionArgs(lc)...)
+	args = append(args, vargs...)
+	for _, k := range sortedKeys(lc.Flags) {
+		args = append(args, vars.Replace(k))
+		if v := lc.Flags[k]; v != "" {
+			args = append(args, vars.Replace(v))
 		}
 	}
 	args = append(args, lc.ReleaseName, lc.ChartPath)
 
+	return args, cleanup, nil
+}
+
+// Down deletes the release.
+func Down(w io.Writer, vars pkg.VarStorer, lc LConfig) error {
+	if err := lc.Validate(); err != nil {
+		return err
+	}
+	lc.setDefaults()
+
+	major, err := Major(lc.BinaryPath)
+	if err != nil {
+		return fmt.Errorf("couldn't detect helm version: %s", err)
+	}
+
+	args := []string{lc.BinaryPath, "uninstall", vars.Replace(lc.ReleaseName), "--namespace", vars.Replace(lc.Namespace)}
+	if major < 3 {
+		args = []string{lc.BinaryPath, "delete", "--purge", vars.Replace(lc.ReleaseName)}
+	}
+	args = append(args, contextArgs(lc)...)
+
+	out, err := run(lc, args)
+	if err != nil {
+		return fmt.Errorf("failed to delete release: %s", err)
+	}
+
+	fmt.Fprintf(w, "%s", out)
+	fmt.Fprintf(w, "Release \"%s\" is deleted.\n", vars.Replace(lc.ReleaseName))
+
+	return nil
+}
+
+// timeoutArgs of the deployment: seconds for Helm 2, duration for Helm 3.
+func timeoutArgs(major int, lc LConfig) []string {
+	if lc.Target.Timeout == 0 {
+		return nil
+	}
+	if major < 3 {
+		return []string{"--timeout", strconv.Itoa(int(lc.Target.Timeout.Seconds()))}
+	}
+
+	return []string{"--timeout", lc.Target.Timeout.String()}
+}
+
+// createNamespaceArgs of the deployment: --create-namespace is supported since Helm 3.2,
+// Helm 2 creates the namespace anyway.
+func createNamespaceArgs(major, minor int, lc LConfig) []string {
+	if major < 3 || (major == 3 && minor < 2) {
+		return nil
+	}
+	if lc.CreateNamespace != nil && !*lc.CreateNamespace {
+		return nil
+	}
+
+	return []string{"--create-namespace"}
+}
+
+func chartVersionArgs(lc LConfig) []string {
+	if lc.ChartVersion == "" {
+		return nil
+	}
+
+	return []string{"--version", lc.ChartVersion}
+}
+
+func contextArgs(lc LConfig) []string {
+	if lc.Target.KubeContext == "" {
+		return nil
+	}
+
+	return []string{"--kube-context", lc.Target.KubeContext}
+}
+
+// Template renders the manifests of the release locally without deploying them.
+func Template(w io.Writer, vars pkg.VarStorer, lc LConfig) error {
+	if err := lc.Validate(); err != nil {
+		return err
+	}
+	lc.setDefaults()
+
+	major, err := Major(lc.BinaryPath)
+	if err != nil {
+		return fmt.Errorf("couldn't detect helm version: %s", err)
+	}
+
+	vargs, cleanup, err := valuesArgs(vars, lc)
+	if err != nil {
+		return err
+	}
+	defer cleanup()
+
+	args := []string{lc.BinaryPath, "template", lc.ReleaseName, lc.ChartPath}
+	if major < 3 {
+		args = []string{lc.BinaryPath, "template", lc.ChartPath, "--name", lc.ReleaseName}
+	}
+	args = append(args, "--namespace", vars.Replace(lc.Namespace))
+	args = append(args, chartVersionArgs(lc)...)
+	args = append(args, vargs...)
+
+	out, err := run(lc, args)
+	if err != nil {
+		return fmt.Errorf("failed to render templates: %s", err)
+	}
+
+	_, err = w.Write(out)
+	return err
+}
+
+// Lint the chart with the same values that are used for the deployment.
+// Lint messages are printed to w, error is returned if the chart has any errors.
+// Remote (OCI) charts are not linted.
+func Lint(w io.Writer, vars pkg.VarStorer, lc LConfig) error {
+	if err := lc.Validate(); err != nil {
+		return err
+	}
+	lc.setDefaults()
+
+	if lc.IsOCI() {
+		fmt.Fprintf(w, "Chart \"%s\" is remote: lint is skipped.\n", lc.ChartPath)
+		return nil
+	}
+
+	vargs, cleanup, err := valuesArgs(vars, lc)
+	if err != nil {
+		return err
+	}
+	defer cleanup()
+
+	args := []string{lc.BinaryPath, "lint", lc.ChartPath, "--namespace", vars.Replace(lc.Namespace)}
+	args = append(args, vargs...)
+
+	cmd := exec.Command(args[0], args[1:]...)
+	if lc.SecretValues != nil {
+		cmd.Stdin = bytes.NewReader(lc.SecretValues)
+	}
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+
+	out, err := cmd.Output()
+	if _, ok := err.(*exec.ExitError); err != nil && !ok {
+		return err
+	}
+
+	var errs int
+	for _, line := range strings.Split(string(out), "\n") {
+		line = strings.TrimSpace(line)
+		switch {
+		case strings.HasPrefix(line, "[ERROR]"):
+			errs++
+		case !strings.HasPrefix(line, "[WARNING]"):
+			continue
+		}
+
+		fmt.Fprintln(w, line)
+	}
+
+	switch {
+	case errs != 0:
+		return fmt.Errorf("chart \"%s\" has %d lint error(s)", lc.ChartPath, errs)
+	case err != nil:
+		return fmt.Errorf("failed to lint chart \"%s\": %s", lc.ChartPath, strings.TrimSpace(stderr.String()))
+	}
+
+	return nil
+}
+
+// run the helm command and return it's output.
+// Secret values are passed via stdin.
+func run(lc LConfig, args []string) ([]byte, error) {
 	cmd := exec.Command(args[0], args[1:]...)
+	if lc.SecretValues != nil {
+		cmd.Stdin = bytes.NewReader(lc.SecretValues)
+	}
 
 	out, err := cmd.Output()
 	if err != nil {
 		ee, ok := err.(*exec.ExitError)
 		if !ok {
-			return err
+			return nil, err
 		}
 
-		return fmt.Errorf("failed to run deployment: error code %d: %s", ee.ExitCode(), strings.TrimSpace(string(ee.Stderr)))
+		return nil, fmt.Errorf("error code %d: %s", ee.ExitCode(), strings.TrimSpace(string(ee.Stderr)))
 	}
 
-	fmt.Fprintln(w, "Deploying:")
-	fmt.Fprintln(w, "----------------------------------------------------")
-	fmt.Fprintf(w, "%s\n", out)
-	fmt.Fprintln(w, "----------------------------------------------------")
-	fmt.Fprintln(w, "Successfully deployed!")
+	return out, nil
+}
 
-	return nil
+// valuesArgs returns the values related arguments in the order helm applies them:
+// values files, inline values, secret values and set values.
+// Returned func removes the temporary files and has to be called once the command is done.
+func valuesArgs(vars pkg.VarStorer, lc LConfig) ([]string, func(), error) {
+	var args []string
+	cleanup := func() {}
+
+	for _, vf := range lc.Values {
+		path := vars.Replace(vf.Path)
+		if _, err := os.Stat(path); err != nil {
+			if vf.Optional && os.IsNotExist(err) {
+				continue
+			}
+
+			return nil, cleanup, fmt.Errorf("values file \"%s\": %s", path, err)
+		}
+
+		args = append(args, "--values", path)
+	}
+
+	if len(lc.InlineValues) != 0 {
+		data, err := yaml.Marshal(lc.InlineValues)
+		if err != nil {
+			return nil, cleanup, fmt.Errorf("couldn't encode inline values: %s", err)
+		}
+
+		path, err := writeTemp(vars.Replace(string(data)))
+		if err != nil {
+			return nil, cleanup, fmt.Errorf("couldn't write inline values: %s", err)
+		}
+		cleanup = func() { os.Remove(path) } // nolint: errcheck
+
+		args = append(args, "--values", path)
+	}
+
+	if lc.SecretValues != nil {
+		args = append(args, "--values", "-")
+	}
+
+	for _, k := range sortedKeys(lc.Set) {
+		args = append(args, "--set", vars.Replace(k)+"="+vars.Replace(lc.Set[k]))
+	}
+
+	return args, cleanup, nil
+}
+
+func writeTemp(data string) (string, error) {
+	f, err := ioutil.TempFile("", "mnqn-values-*.yaml")
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	if _, err := f.WriteString(data); err != nil {
+		os.Remove(f.Name()) // nolint: errcheck
+		return "", err
+	}
+
+	return f.Name(), nil
+}
+
+func sortedKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	return keys
 }
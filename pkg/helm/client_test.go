@@ -0,0 +1,162 @@
+package helm
+
+import (
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestCreateNamespaceArgs(t *testing.T) {
+	no := false
+	yes := true
+
+	tests := []struct {
+		name   string
+		major  int
+		minor  int
+		create *bool
+		want   []string
+	}{
+		{name: "helm 2", major: 2, minor: 16},
+		{name: "helm 3.0", major: 3, minor: 0},
+		{name: "helm 3.1", major: 3, minor: 1},
+		{name: "helm 3.2", major: 3, minor: 2, want: []string{"--create-namespace"}},
+		{name: "helm 3.12 explicitly", major: 3, minor: 12, create: &yes, want: []string{"--create-namespace"}},
+		{name: "helm 4", major: 4, minor: 0, want: []string{"--create-namespace"}},
+		{name: "opted out", major: 3, minor: 12, create: &no},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := createNamespaceArgs(tt.major, tt.minor, LConfig{CreateNamespace: tt.create})
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("expected %q, got %q", tt.want, got)
+			}
+		})
+	}
+}
+
+// testVars is the VarStorer of the tests.
+type testVars map[string]string
+
+func (tv testVars) Register(name, val string) error {
+	tv[name] = val
+	return nil
+}
+
+func (tv testVars) Replace(value string) string {
+	for k, v := range tv {
+		value = strings.Replace(value, "$"+k, v, -1)
+	}
+	return value
+}
+
+func (tv testVars) Var(name string) (string, error) {
+	if v, ok := tv[name]; ok {
+		return v, nil
+	}
+	return "", errors.New("variable is not set")
+}
+
+func TestValuesArgs(t *testing.T) {
+	dir, err := ioutil.TempDir("", "helm")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	for _, name := range []string{"values.yaml", "dev.yaml"} {
+		if err := ioutil.WriteFile(filepath.Join(dir, name), []byte("a: 1\n"), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	path := func(name string) string {
+		return filepath.Join(dir, name)
+	}
+	vars := testVars{"DIR": dir, "TAG": "1"}
+
+	tests := []struct {
+		name string
+		lc   LConfig
+		want []string
+		err  string
+	}{
+		{
+			name: "values files in order",
+			lc: LConfig{Values: ValuesFiles{
+				{Path: "$DIR/values.yaml"},
+				{Path: "$DIR/missing.yaml", Optional: true},
+				{Path: "$DIR/dev.yaml", Optional: true},
+			}},
+			want: []string{"--values", path("values.yaml"), "--values", path("dev.yaml")},
+		},
+		{
+			name: "missing required file",
+			lc:   LConfig{Values: ValuesFiles{{Path: "$DIR/missing.yaml"}}},
+			err:  "values file \"" + path("missing.yaml") + "\"",
+		},
+		{
+			name: "files, secrets and sorted set values",
+			lc: LConfig{
+				Values:       ValuesFiles{{Path: path("dev.yaml")}},
+				SecretValues: []byte("secret: value\n"),
+				Set:          map[string]string{"image.tag": "$TAG", "a": "b", "z": "y"},
+			},
+			want: []string{"--values", path("dev.yaml"), "--values", "-", "--set", "a=b", "--set", "image.tag=1", "--set", "z=y"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			args, cleanup, err := valuesArgs(vars, tt.lc)
+			defer cleanup()
+			if tt.err != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.err) {
+					t.Fatalf("expected error containing \"%s\", got %v", tt.err, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(args, tt.want) {
+				t.Errorf("expected %q, got %q", tt.want, args)
+			}
+		})
+	}
+}
+
+func TestValuesArgsInline(t *testing.T) {
+	lc := LConfig{
+		Values:       ValuesFiles{{Path: "values.yaml", Optional: true}},
+		InlineValues: map[string]interface{}{"image": map[string]string{"tag": "$TAG"}},
+		SecretValues: []byte("secret: value\n"),
+		Set:          map[string]string{"a": "b"},
+	}
+
+	args, cleanup, err := valuesArgs(testVars{"TAG": "1"}, lc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// inline values go after the files, so they override them, secrets and --set go last.
+	if len(args) != 6 || args[0] != "--values" || args[2] != "--values" || args[3] != "-" || args[4] != "--set" {
+		t.Fatalf("unexpected arguments %q", args)
+	}
+
+	data, err := ioutil.ReadFile(args[1])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != "image:\n  tag: 1\n" {
+		t.Errorf("unexpected inline values %q", data)
+	}
+
+	cleanup()
+	if _, err := os.Stat(args[1]); !os.IsNotExist(err) {
+		t.Errorf("inline values are not removed: %v", err)
+	}
+}
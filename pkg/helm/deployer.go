@@ -0,0 +1,69 @@
+package helm
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/kostkobv/mannequin/pkg"
+)
+
+// Deployer deploys the chart with helm.
+type Deployer struct {
+	lc LConfig
+}
+
+// NewDeployer is a constructor for Deployer.
+func NewDeployer(lc LConfig, t pkg.Target) Deployer {
+	lc.Target = t
+	return Deployer{lc: lc}
+}
+
+// Check impl.
+func (d Deployer) Check() error {
+	if _, err := CheckInstalled(d.lc.BinaryPath); err != nil {
+		return fmt.Errorf("helm: %s", err)
+	}
+
+	return nil
+}
+
+// Lint impl.
+func (d Deployer) Lint(w io.Writer, vars pkg.VarStorer) error {
+	return Lint(w, vars, d.lc)
+}
+
+// Plan impl.
+func (d Deployer) Plan(w io.Writer, vars pkg.VarStorer) error {
+	WarnDeprecated(w, d.lc.BinaryPath)
+
+	args, cleanup, err := UpgradeArgs(vars, d.lc)
+	if err != nil {
+		return fmt.Errorf("couldn't prepare deployment: %s", err)
+	}
+	defer cleanup()
+
+	fmt.Fprintln(w, "Release would be deployed with:")
+	fmt.Fprintln(w, "----------------------------------------------------")
+	fmt.Fprintln(w, pkg.Cmdline(args))
+	fmt.Fprintln(w, "----------------------------------------------------")
+	fmt.Fprintln(w, "Rendered manifests:")
+	fmt.Fprintln(w, "----------------------------------------------------")
+	if err := Template(w, vars, d.lc); err != nil {
+		return err
+	}
+	fmt.Fprintln(w, "----------------------------------------------------")
+
+	return nil
+}
+
+// Deploy impl.
+func (d Deployer) Deploy(w io.Writer, vars pkg.VarStorer) error {
+	WarnDeprecated(w, d.lc.BinaryPath)
+
+	return Deploy(w, vars, d.lc)
+}
+
+// Down impl.
+func (d Deployer) Down(w io.Writer, vars pkg.VarStorer) error {
+	return Down(w, vars, d.lc)
+}
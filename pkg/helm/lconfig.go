@@ -2,6 +2,10 @@ package helm
 
 import (
 	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/kostkobv/mannequin/pkg"
 )
 
 // LConfig of Helm values.
@@ -22,13 +26,27 @@ import (
        $HELM_CHART
 */
 type LConfig struct {
-	BinaryPath  string            `yaml:"binary_path,omitempty,flow"`
-	ValuesPath  string            `yaml:"values,omitempty,flow"`
-	Namespace   string            `yaml:"namespace,omitempty,flow"`
-	Set         map[string]string `yaml:"set,omitempty,flow"`
-	Flags       map[string]string `yaml:"flags,omitempty,flow"`
-	ReleaseName string            `yaml:"release_name,omitempty,flow"`
-	ChartPath   string            `yaml:"chart,flow"`
+	BinaryPath   string                 `yaml:"binary_path,omitempty,flow"`
+	Values       ValuesFiles            `yaml:"values,omitempty,flow"`
+	InlineValues map[string]interface{} `yaml:"inline_values,omitempty"`
+	SecretsPath  string                 `yaml:"secrets,omitempty,flow"`
+	Namespace    string                 `yaml:"namespace,omitempty,flow"`
+	Set          map[string]string      `yaml:"set,omitempty,flow"`
+	Flags        map[string]string      `yaml:"flags,omitempty,flow"`
+	ReleaseName  string                 `yaml:"release_name,omitempty,flow"`
+	ChartPath    string                 `yaml:"chart,flow"`
+	// ChartVersion of the chart from the repository or OCI registry.
+	ChartVersion string `yaml:"chart_version,omitempty,flow"`
+	// Atomic rolls back the failed deployment.
+	Atomic bool `yaml:"atomic,omitempty,flow"`
+	// CreateNamespace of the release if it's missing (Helm 3), enabled by default.
+	CreateNamespace *bool `yaml:"create_namespace,omitempty,flow"`
+
+	// SecretValues are the decrypted values of the SecretsPath file.
+	// Never persisted and passed to helm via stdin.
+	SecretValues []byte `yaml:"-"`
+	// Target is used by every helm command that talks to the cluster.
+	Target pkg.Target `yaml:"-"`
 }
 
 // New is a constructor for LConfig.
@@ -56,5 +74,181 @@ func (lc *LConfig) Validate() error {
 		return errors.New("release name is required")
 	}
 
+	for i, vf := range lc.Values {
+		if vf.Path == "" {
+			return fmt.Errorf("values file #%d: path is required", i+1)
+		}
+	}
+
+	return nil
+}
+
+// ValuesFile is a helm values file.
+// Optional file is skipped if it's missing.
+type ValuesFile struct {
+	Path     string `yaml:"path"`
+	Optional bool   `yaml:"optional,omitempty"`
+}
+
+// UnmarshalYAML impl.
+// ValuesFile could be defined either as a path or as a map.
+func (vf *ValuesFile) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	var path string
+	if err := unmarshal(&path); err == nil {
+		*vf = ValuesFile{Path: path}
+		return nil
+	}
+
+	type plain ValuesFile
+	return unmarshal((*plain)(vf))
+}
+
+// MarshalYAML impl.
+// Required ValuesFile is written as a path.
+func (vf ValuesFile) MarshalYAML() (interface{}, error) {
+	if !vf.Optional {
+		return vf.Path, nil
+	}
+
+	type plain ValuesFile
+	return plain(vf), nil
+}
+
+// ValuesFiles is an ordered list of ValuesFile.
+// The latter ones override the former ones.
+type ValuesFiles []ValuesFile
+
+// UnmarshalYAML impl.
+// Single path is supported to keep the configs that define only one values file working.
+func (vfs *ValuesFiles) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	var path string
+	if err := unmarshal(&path); err == nil {
+		*vfs = ValuesFiles{{Path: path}}
+		return nil
+	}
+
+	var files []ValuesFile
+	if err := unmarshal(&files); err != nil {
+		return err
+	}
+
+	*vfs = files
 	return nil
 }
+
+func (lc *LConfig) setDefaults() {
+	if lc.BinaryPath == "" {
+		lc.BinaryPath = defaultBinPath
+	}
+	if lc.Namespace == "" {
+		lc.Namespace = lc.ReleaseName
+	}
+}
+
+// IsOCI reports if the chart is the OCI registry reference (oci://...).
+func (lc *LConfig) IsOCI() bool {
+	return strings.HasPrefix(lc.ChartPath, ociPrefix)
+}
+
+// Merge the provided LConfig into the current one.
+// Only set values of the provided LConfig override the current ones:
+// values files are appended, maps are merged key by key.
+func (lc *LConfig) Merge(o LConfig) {
+	if o.BinaryPath != "" {
+		lc.BinaryPath = o.BinaryPath
+	}
+	if o.Namespace != "" {
+		lc.Namespace = o.Namespace
+	}
+	if o.ReleaseName != "" {
+		lc.ReleaseName = o.ReleaseName
+	}
+	if o.ChartPath != "" {
+		lc.ChartPath = o.ChartPath
+	}
+	if o.SecretsPath != "" {
+		lc.SecretsPath = o.SecretsPath
+	}
+	if o.ChartVersion != "" {
+		lc.ChartVersion = o.ChartVersion
+	}
+	if o.Atomic {
+		lc.Atomic = true
+	}
+	if o.CreateNamespace != nil {
+		lc.CreateNamespace = o.CreateNamespace
+	}
+
+	lc.Values = append(lc.Values, o.Values...)
+	lc.Set = mergeStrings(lc.Set, o.Set)
+	lc.Flags = mergeStrings(lc.Flags, o.Flags)
+
+	if len(o.InlineValues) != 0 {
+		if lc.InlineValues == nil {
+			lc.InlineValues = map[string]interface{}{}
+		}
+		for k, v := range o.InlineValues {
+			lc.InlineValues[k] = mergeValues(lc.InlineValues[k], v)
+		}
+	}
+}
+
+func mergeStrings(dst, src map[string]string) map[string]string {
+	if len(src) == 0 {
+		return dst
+	}
+	if dst == nil {
+		dst = map[string]string{}
+	}
+	for k, v := range src {
+		dst[k] = v
+	}
+
+	return dst
+}
+
+// mergeValues merges nested maps the same way helm merges values files.
+func mergeValues(dst, src interface{}) interface{} {
+	dm, ok := dst.(map[interface{}]interface{})
+	if !ok {
+		return src
+	}
+	sm, ok := src.(map[interface{}]interface{})
+	if !ok {
+		return src
+	}
+
+	for k, v := range sm {
+		dm[k] = mergeValues(dm[k], v)
+	}
+
+	return dm
+}
+
+// JSONSchema impl.
+func (vf ValuesFile) JSONSchema() map[string]interface{} {
+	return map[string]interface{}{
+		"oneOf": []interface{}{
+			map[string]interface{}{"type": "string"},
+			map[string]interface{}{
+				"type":                 "object",
+				"required":             []string{"path"},
+				"additionalProperties": false,
+				"properties": map[string]interface{}{
+					"path":     map[string]interface{}{"type": "string"},
+					"optional": map[string]interface{}{"type": "boolean"},
+				},
+			},
+		},
+	}
+}
+
+// JSONSchema impl.
+func (vfs ValuesFiles) JSONSchema() map[string]interface{} {
+	return map[string]interface{}{
+		"oneOf": []interface{}{
+			map[string]interface{}{"type": "string"},
+			map[string]interface{}{"type": "array", "items": ValuesFile{}.JSONSchema()},
+		},
+	}
+}
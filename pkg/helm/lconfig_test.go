@@ -0,0 +1,94 @@
+package helm
+
+import (
+	"reflect"
+	"testing"
+
+	"gopkg.in/yaml.v2"
+)
+
+func TestValuesFilesUnmarshal(t *testing.T) {
+	tests := []struct {
+		name string
+		doc  string
+		want ValuesFiles
+		err  bool
+	}{
+		{name: "single path", doc: "values: values.yaml\n", want: ValuesFiles{{Path: "values.yaml"}}},
+		{
+			name: "list of paths",
+			doc:  "values:\n  - values.yaml\n  - local.yaml\n",
+			want: ValuesFiles{{Path: "values.yaml"}, {Path: "local.yaml"}},
+		},
+		{
+			name: "list of paths and maps",
+			doc:  "values:\n  - values.yaml\n  - path: local.yaml\n    optional: true\n",
+			want: ValuesFiles{{Path: "values.yaml"}, {Path: "local.yaml", Optional: true}},
+		},
+		{name: "no values", doc: "chart: chart\n"},
+		{name: "invalid", doc: "values:\n  path: values.yaml\n", err: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var lc LConfig
+			err := yaml.UnmarshalStrict([]byte(tt.doc), &lc)
+			if tt.err {
+				if err == nil {
+					t.Fatal("expected an error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(lc.Values, tt.want) {
+				t.Errorf("expected %+v, got %+v", tt.want, lc.Values)
+			}
+
+			// values files survive the round trip.
+			data, err := yaml.Marshal(lc)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var again LConfig
+			if err := yaml.UnmarshalStrict(data, &again); err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(again.Values, tt.want) {
+				t.Errorf("expected %+v after the round trip, got %+v", tt.want, again.Values)
+			}
+		})
+	}
+}
+
+func TestMerge(t *testing.T) {
+	no := false
+	lc := LConfig{
+		ChartPath:    "chart",
+		ReleaseName:  "app",
+		Values:       ValuesFiles{{Path: "values.yaml"}},
+		Set:          map[string]string{"a": "1", "b": "2"},
+		InlineValues: map[string]interface{}{"env": map[interface{}]interface{}{"A": "1", "B": "2"}},
+	}
+	lc.Merge(LConfig{
+		Namespace:       "dev",
+		Values:          ValuesFiles{{Path: "dev.yaml", Optional: true}},
+		Set:             map[string]string{"b": "3"},
+		InlineValues:    map[string]interface{}{"env": map[interface{}]interface{}{"B": "3"}},
+		CreateNamespace: &no,
+	})
+
+	want := LConfig{
+		ChartPath:       "chart",
+		ReleaseName:     "app",
+		Namespace:       "dev",
+		Values:          ValuesFiles{{Path: "values.yaml"}, {Path: "dev.yaml", Optional: true}},
+		Set:             map[string]string{"a": "1", "b": "3"},
+		InlineValues:    map[string]interface{}{"env": map[interface{}]interface{}{"A": "1", "B": "3"}},
+		CreateNamespace: &no,
+	}
+	if !reflect.DeepEqual(lc, want) {
+		t.Errorf("expected %+v, got %+v", want, lc)
+	}
+}
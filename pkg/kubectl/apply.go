@@ -0,0 +1,145 @@
+package kubectl
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os/exec"
+	"strconv"
+	"strings"
+
+	"github.com/kostkobv/mannequin/pkg"
+
+	"gopkg.in/yaml.v2"
+)
+
+// kinds that are rolled out and could be waited for.
+var rolloutKinds = map[string]bool{"Deployment": true, "StatefulSet": true, "DaemonSet": true}
+
+// Object is the kubernetes object found in the manifests.
+type Object struct {
+	APIVersion string `yaml:"apiVersion"`
+	Kind       string `yaml:"kind"`
+	Metadata   struct {
+		Name      string            `yaml:"name"`
+		Namespace string            `yaml:"namespace"`
+		Labels    map[string]string `yaml:"labels"`
+	} `yaml:"metadata"`
+}
+
+// Ref of the object in kind/name format.
+func (o Object) Ref() string {
+	return strings.ToLower(o.Kind) + "/" + o.Metadata.Name
+}
+
+// Objects returns the objects of the multi-document manifests.
+// Empty documents are skipped, lists are not expanded.
+func Objects(manifests []byte) ([]Object, error) {
+	var res []Object
+
+	d := yaml.NewDecoder(bytes.NewReader(manifests))
+	for {
+		var o Object
+		err := d.Decode(&o)
+		if err == io.EOF {
+			return res, nil
+		}
+		if err != nil {
+			return nil, fmt.Errorf("couldn't parse manifests: %s", err)
+		}
+
+		if o.Kind != "" {
+			res = append(res, o)
+		}
+	}
+}
+
+// Run kubectl with the provided context and returns it's output.
+// stdin is passed to the command if provided.
+func Run(k8sCtx string, stdin []byte, args ...string) ([]byte, error) {
+	if k8sCtx != "" {
+		args = append([]string{"--context", k8sCtx}, args...)
+	}
+
+	cmd := exec.Command("kubectl", args...)
+	if stdin != nil {
+		cmd.Stdin = bytes.NewReader(stdin)
+	}
+
+	out, err := cmd.Output()
+	if err != nil {
+		ee, ok := err.(*exec.ExitError)
+		if !ok {
+			return nil, err
+		}
+
+		return nil, fmt.Errorf("error code %d: %s", ee.ExitCode(), strings.TrimSpace(string(ee.Stderr)))
+	}
+
+	return out, nil
+}
+
+// Kustomize renders the kustomization of the dir.
+func Kustomize(dir string) ([]byte, error) {
+	return Run("", nil, "kustomize", dir)
+}
+
+// WaitRollout waits until every workload of the manifests is rolled out.
+// Objects without namespace are expected in the provided namespace.
+func WaitRollout(w io.Writer, t pkg.Target, namespace string, manifests []byte) error {
+	objs, err := Objects(manifests)
+	if err != nil {
+		return err
+	}
+
+	for _, o := range objs {
+		if !rolloutKinds[o.Kind] {
+			continue
+		}
+
+		ns := o.Metadata.Namespace
+		if ns == "" {
+			ns = namespace
+		}
+
+		args := []string{"rollout", "status", o.Ref()}
+		if ns != "" {
+			args = append(args, "--namespace", ns)
+		}
+		if t.Timeout != 0 {
+			args = append(args, "--timeout", strconv.Itoa(int(t.Timeout.Seconds()))+"s")
+		}
+
+		fmt.Fprintf(w, "Waiting for %s to be rolled out.\n", o.Ref())
+		if _, err := Run(t.KubeContext, nil, args...); err != nil {
+			return fmt.Errorf("%s is not rolled out: %s", o.Ref(), err)
+		}
+	}
+
+	return nil
+}
+
+// Get the objects of the kinds that match the label selector.
+// Objects of every namespace are returned if namespace is not provided.
+func Get(k8sCtx, namespace string, kinds []string, selector string) ([]Object, error) {
+	args := []string{"get", strings.Join(kinds, ","), "--selector", selector, "--ignore-not-found", "--output", "yaml"}
+	if namespace != "" {
+		args = append(args, "--namespace", namespace)
+	} else {
+		args = append(args, "--all-namespaces")
+	}
+
+	out, err := Run(k8sCtx, nil, args...)
+	if err != nil {
+		return nil, err
+	}
+
+	var list struct {
+		Items []Object `yaml:"items"`
+	}
+	if err := yaml.Unmarshal(out, &list); err != nil {
+		return nil, fmt.Errorf("couldn't parse objects: %s", err)
+	}
+
+	return list.Items, nil
+}
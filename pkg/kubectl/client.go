@@ -1,65 +1,106 @@
 package kubectl
 
 import (
+	"encoding/json"
 	"errors"
 	"fmt"
 	"os/exec"
-	"regexp"
 	"strings"
 )
 
-var checkVer = regexp.MustCompile(`GitVersion:"(v\d+.\d+.\d+)"`)
-
+// CheckInstalled returns the version of the kubectl client.
 func CheckInstalled() (string, error) {
-	cmd := exec.Command("kubectl", "version", "--client")
-	out, err := cmd.Output()
+	out, err := exec.Command("kubectl", "version", "--client", "--output", "json").Output()
 	if err != nil {
-		return "", err
+		ee, ok := err.(*exec.ExitError)
+		if !ok {
+			return "", errors.New("kubectl is not installed")
+		}
+		return "", fmt.Errorf("couldn't get kubectl version: %s", strings.TrimSpace(string(ee.Stderr)))
 	}
 
-	res := checkVer.FindStringSubmatch(string(out))
-	if len(res) < 1 {
-		return "", errors.New("kubectl is not installed")
+	var ver struct {
+		ClientVersion struct {
+			GitVersion string `json:"gitVersion"`
+		} `json:"clientVersion"`
+	}
+	if err := json.Unmarshal(out, &ver); err != nil {
+		return "", fmt.Errorf("couldn't parse kubectl version: %s", err)
+	}
+	if ver.ClientVersion.GitVersion == "" {
+		return "", errors.New("kubectl version is not found")
 	}
 
-	return res[1], nil
+	return ver.ClientVersion.GitVersion, nil
 }
 
-func CheckContext(expected string) error {
-	cmd := exec.Command("kubectl", "config", "current-context")
+// Contexts returns the names of the contexts available in kubeconfig.
+func Contexts() ([]string, error) {
+	cmd := exec.Command("kubectl", "config", "get-contexts", "-o", "name")
 	out, err := cmd.Output()
 	if err != nil {
-		return err
+		return nil, err
 	}
 
-	k8sCtx := strings.TrimSpace(string(out))
-	if k8sCtx != expected {
-		return fmt.Errorf("unexpected context %s: expected %s", k8sCtx, expected)
+	var res []string
+	for _, l := range strings.Split(string(out), "\n") {
+		if l = strings.TrimSpace(l); l != "" {
+			res = append(res, l)
+		}
 	}
 
-	return nil
+	return res, nil
 }
 
-func UseContext(expected string) error {
-	cmd := exec.Command("kubectl", "config", "use-context", expected)
+// Server returns the API server address of the context.
+func Server(k8sCtx string) (string, error) {
+	cmd := exec.Command("kubectl", "config", "view", "--minify", "--context", k8sCtx,
+		"-o", "jsonpath={.clusters[0].cluster.server}")
 	out, err := cmd.Output()
+	if err != nil {
+		ee, ok := err.(*exec.ExitError)
+		if !ok {
+			return "", err
+		}
+
+		return "", fmt.Errorf("couldn't read context %s: %s", k8sCtx, strings.TrimSpace(string(ee.Stderr)))
+	}
+
+	return strings.TrimSpace(string(out)), nil
+}
+
+// CheckContext if the context exists in kubeconfig.
+func CheckContext(expected string) error {
+	ctxs, err := Contexts()
 	if err != nil {
 		return err
 	}
 
-	k8sCtx := strings.TrimSpace(string(out))
-	if k8sCtx != `Switched to context "`+expected+`".` {
-		return fmt.Errorf("couldn't set context %s: %s", expected, k8sCtx)
+	for _, c := range ctxs {
+		if c == expected {
+			return nil
+		}
 	}
 
-	return nil
+	return fmt.Errorf("context %s is not found in kubeconfig", expected)
 }
 
+// CheckAndUseContext checks if the context could be used: it exists in kubeconfig
+// and it's API server is reachable.
+// Kubeconfig is never modified, every command receives the context explicitly instead.
 func CheckAndUseContext(expected string) error {
 	if err := CheckContext(expected); err != nil {
-		if err := UseContext(expected); err != nil {
+		return err
+	}
+
+	cmd := exec.Command("kubectl", "--context", expected, "get", "--raw", "/version")
+	if _, err := cmd.Output(); err != nil {
+		ee, ok := err.(*exec.ExitError)
+		if !ok {
 			return err
 		}
+
+		return fmt.Errorf("context %s is not reachable: %s", expected, strings.TrimSpace(string(ee.Stderr)))
 	}
 
 	return nil
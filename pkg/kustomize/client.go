@@ -0,0 +1,193 @@
+package kustomize
+
+import (
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strconv"
+
+	"github.com/kostkobv/mannequin/pkg"
+	"github.com/kostkobv/mannequin/pkg/kubectl"
+
+	"gopkg.in/yaml.v2"
+)
+
+// Deployer applies the kustomization with kubectl.
+// Image of the manifests is replaced with the built one via kustomize image override.
+type Deployer struct {
+	lc       LConfig
+	imageRef string
+	tagRef   string
+}
+
+// NewDeployer is a constructor for Deployer.
+// Built image name and tag are provided as the references to the variables,
+// so they are resolved at the time of the deployment.
+func NewDeployer(lc LConfig, t pkg.Target, imageRef, tagRef string) Deployer {
+	lc.Target = t
+	return Deployer{lc: lc, imageRef: imageRef, tagRef: tagRef}
+}
+
+// Check impl.
+func (d Deployer) Check() error {
+	if _, err := kubectl.CheckInstalled(); err != nil {
+		return fmt.Errorf("kubectl: %s", err)
+	}
+
+	return nil
+}
+
+// Lint impl.
+// Kustomization is rendered, so broken overlays and patches are reported before the deployment.
+func (d Deployer) Lint(w io.Writer, vars pkg.VarStorer) error {
+	_, err := d.render(vars)
+	return err
+}
+
+// Plan impl.
+func (d Deployer) Plan(w io.Writer, vars pkg.VarStorer) error {
+	manifests, err := d.render(vars)
+	if err != nil {
+		return err
+	}
+
+	fmt.Fprintln(w, "Manifests would be applied with:")
+	fmt.Fprintln(w, "----------------------------------------------------")
+	fmt.Fprintln(w, pkg.Cmdline(d.kubectlArgs("apply", "-f", "-")))
+	fmt.Fprintln(w, "----------------------------------------------------")
+	fmt.Fprintln(w, "Rendered manifests:")
+	fmt.Fprintln(w, "----------------------------------------------------")
+	fmt.Fprintf(w, "%s", manifests)
+	fmt.Fprintln(w, "----------------------------------------------------")
+
+	return nil
+}
+
+// Deploy impl.
+func (d Deployer) Deploy(w io.Writer, vars pkg.VarStorer) error {
+	manifests, err := d.render(vars)
+	if err != nil {
+		return err
+	}
+
+	out, err := kubectl.Run(d.lc.Target.KubeContext, manifests, "apply", "-f", "-")
+	if err != nil {
+		return fmt.Errorf("failed to apply manifests: %s", err)
+	}
+
+	fmt.Fprintln(w, "Deploying:")
+	fmt.Fprintln(w, "----------------------------------------------------")
+	fmt.Fprintf(w, "%s", out)
+	fmt.Fprintln(w, "----------------------------------------------------")
+
+	if d.lc.Target.Wait {
+		if err := kubectl.WaitRollout(w, d.lc.Target, vars.Replace(d.lc.Namespace), manifests); err != nil {
+			return err
+		}
+	}
+	fmt.Fprintln(w, "Successfully deployed!")
+
+	return nil
+}
+
+// Down impl.
+func (d Deployer) Down(w io.Writer, vars pkg.VarStorer) error {
+	manifests, err := d.render(vars)
+	if err != nil {
+		return err
+	}
+
+	out, err := kubectl.Run(d.lc.Target.KubeContext, manifests, "delete", "--ignore-not-found", "-f", "-")
+	if err != nil {
+		return fmt.Errorf("failed to delete manifests: %s", err)
+	}
+
+	fmt.Fprintf(w, "%s", out)
+
+	return nil
+}
+
+func (d Deployer) kubectlArgs(args ...string) []string {
+	res := []string{"kubectl"}
+	if d.lc.Target.KubeContext != "" {
+		res = append(res, "--context", d.lc.Target.KubeContext)
+	}
+
+	return append(res, args...)
+}
+
+// render the kustomization with the image override, namespace and patches.
+// Overrides are put to the temporary kustomization that uses the configured one as a resource.
+func (d Deployer) render(vars pkg.VarStorer) ([]byte, error) {
+	if err := d.lc.Validate(); err != nil {
+		return nil, err
+	}
+
+	dir, err := ioutil.TempDir("", "mnqn-kustomize")
+	if err != nil {
+		return nil, err
+	}
+	defer os.RemoveAll(dir) // nolint: errcheck
+
+	if err := d.overlay(dir, vars); err != nil {
+		return nil, err
+	}
+
+	out, err := kubectl.Kustomize(dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to render kustomization \"%s\": %s", d.lc.Path, err)
+	}
+
+	return out, nil
+}
+
+// overlay writes the kustomization with the overrides and the patches with the variables expanded to the dir.
+func (d Deployer) overlay(dir string, vars pkg.VarStorer) error {
+	path, err := filepath.Abs(vars.Replace(d.lc.Path))
+	if err != nil {
+		return err
+	}
+	if i, err := os.Stat(path); err != nil || !i.IsDir() {
+		return fmt.Errorf("kustomization \"%s\" is not found", d.lc.Path)
+	}
+
+	k := yaml.MapSlice{
+		{Key: "apiVersion", Value: "kustomize.config.k8s.io/v1beta1"},
+		{Key: "kind", Value: "Kustomization"},
+		{Key: "resources", Value: []string{path}},
+		{Key: "images", Value: []map[string]string{{
+			"name":    vars.Replace(d.lc.Image),
+			"newName": vars.Replace(d.imageRef),
+			"newTag":  vars.Replace(d.tagRef),
+		}}},
+	}
+	if d.lc.Namespace != "" {
+		k = append(k, yaml.MapItem{Key: "namespace", Value: vars.Replace(d.lc.Namespace)})
+	}
+
+	var patches []string
+	for i, p := range d.lc.Patches {
+		data, err := ioutil.ReadFile(vars.Replace(p))
+		if err != nil {
+			return fmt.Errorf("couldn't read patch: %s", err)
+		}
+
+		name := "patch-" + strconv.Itoa(i+1) + ".yaml"
+		if err := ioutil.WriteFile(filepath.Join(dir, name), []byte(vars.Replace(string(data))), 0600); err != nil {
+			return fmt.Errorf("couldn't write patch: %s", err)
+		}
+		patches = append(patches, name)
+	}
+	if len(patches) != 0 {
+		k = append(k, yaml.MapItem{Key: "patchesStrategicMerge", Value: patches})
+	}
+
+	data, err := yaml.Marshal(k)
+	if err != nil {
+		return err
+	}
+
+	return ioutil.WriteFile(filepath.Join(dir, "kustomization.yaml"), data, 0600)
+}
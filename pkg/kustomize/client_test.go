@@ -0,0 +1,153 @@
+package kustomize
+
+import (
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/kostkobv/mannequin/pkg"
+
+	"gopkg.in/yaml.v2"
+)
+
+// testVars is the VarStorer of the tests.
+type testVars map[string]string
+
+func (tv testVars) Register(name, val string) error {
+	tv[name] = val
+	return nil
+}
+
+func (tv testVars) Replace(value string) string {
+	for k, v := range tv {
+		value = strings.Replace(value, "$"+k, v, -1)
+	}
+	return value
+}
+
+func (tv testVars) Var(name string) (string, error) {
+	if v, ok := tv[name]; ok {
+		return v, nil
+	}
+	return "", errors.New("variable is not set")
+}
+
+func TestOverlay(t *testing.T) {
+	src, err := ioutil.TempDir("", "kustomize")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(src)
+
+	base := filepath.Join(src, "base")
+	if err := os.Mkdir(base, 0755); err != nil {
+		t.Fatal(err)
+	}
+	patch := filepath.Join(src, "replicas.yaml")
+	if err := ioutil.WriteFile(patch, []byte("kind: Deployment\nmetadata:\n  name: $NAME\nspec:\n  replicas: 2\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	vars := testVars{
+		"NAME":          "app",
+		"NS":            "dev",
+		"IMAGE_NAME":    "mnqn.local/app",
+		"IMAGE_VERSION": "1",
+	}
+
+	tests := []struct {
+		name    string
+		lc      LConfig
+		want    map[string]interface{}
+		patches map[string]string
+		err     string
+	}{
+		{
+			name: "image override",
+			lc:   LConfig{Path: base, Image: "$NAME"},
+			want: map[string]interface{}{
+				"apiVersion": "kustomize.config.k8s.io/v1beta1",
+				"kind":       "Kustomization",
+				"resources":  []interface{}{base},
+				"images": []interface{}{map[interface{}]interface{}{
+					"name": "app", "newName": "mnqn.local/app", "newTag": "1",
+				}},
+			},
+		},
+		{
+			name: "namespace and patches",
+			lc:   LConfig{Path: base, Image: "app", Namespace: "$NS", Patches: []string{patch}},
+			want: map[string]interface{}{
+				"apiVersion": "kustomize.config.k8s.io/v1beta1",
+				"kind":       "Kustomization",
+				"resources":  []interface{}{base},
+				"images": []interface{}{map[interface{}]interface{}{
+					"name": "app", "newName": "mnqn.local/app", "newTag": "1",
+				}},
+				"namespace":             "dev",
+				"patchesStrategicMerge": []interface{}{"patch-1.yaml"},
+			},
+			patches: map[string]string{
+				"patch-1.yaml": "kind: Deployment\nmetadata:\n  name: app\nspec:\n  replicas: 2\n",
+			},
+		},
+		{
+			name: "missing kustomization",
+			lc:   LConfig{Path: filepath.Join(src, "missing"), Image: "app"},
+			err:  "is not found",
+		},
+		{
+			name: "missing patch",
+			lc:   LConfig{Path: base, Image: "app", Patches: []string{filepath.Join(src, "missing.yaml")}},
+			err:  "couldn't read patch",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir, err := ioutil.TempDir("", "kustomize")
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer os.RemoveAll(dir)
+
+			d := NewDeployer(tt.lc, pkg.Target{}, "$IMAGE_NAME", "$IMAGE_VERSION")
+			err = d.overlay(dir, vars)
+			if tt.err != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.err) {
+					t.Fatalf("expected error containing \"%s\", got %v", tt.err, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			data, err := ioutil.ReadFile(filepath.Join(dir, "kustomization.yaml"))
+			if err != nil {
+				t.Fatal(err)
+			}
+			var got map[string]interface{}
+			if err := yaml.Unmarshal(data, &got); err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("expected %v, got %v", tt.want, got)
+			}
+
+			for name, body := range tt.patches {
+				data, err := ioutil.ReadFile(filepath.Join(dir, name))
+				if err != nil {
+					t.Fatal(err)
+				}
+				if string(data) != body {
+					t.Errorf("%s: expected %q, got %q", name, body, data)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,56 @@
+package kustomize
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/kostkobv/mannequin/pkg"
+)
+
+// LConfig of the kustomize deployment.
+// Image is the name of the image in the manifests that is replaced with the built one.
+// Patches are strategic merge patches applied on top of the kustomization,
+// variables are expanded in them.
+type LConfig struct {
+	Path      string   `yaml:"path,omitempty,flow"`
+	Namespace string   `yaml:"namespace,omitempty,flow"`
+	Image     string   `yaml:"image,omitempty,flow"`
+	Patches   []string `yaml:"patches,omitempty,flow"`
+
+	// Target is used by every command that talks to the cluster.
+	Target pkg.Target `yaml:"-"`
+}
+
+// Validate the LConfig.
+func (lc *LConfig) Validate() error {
+	switch {
+	case lc.Path == "":
+		return errors.New("kustomization path is required")
+	case lc.Image == "":
+		return errors.New("image is required")
+	}
+
+	for i, p := range lc.Patches {
+		if p == "" {
+			return fmt.Errorf("patch #%d: path is required", i+1)
+		}
+	}
+
+	return nil
+}
+
+// Merge overrides the LConfig with the set fields of o.
+// Patches are appended.
+func (lc *LConfig) Merge(o LConfig) {
+	if o.Path != "" {
+		lc.Path = o.Path
+	}
+	if o.Namespace != "" {
+		lc.Namespace = o.Namespace
+	}
+	if o.Image != "" {
+		lc.Image = o.Image
+	}
+
+	lc.Patches = append(lc.Patches, o.Patches...)
+}
@@ -0,0 +1,366 @@
+package manifests
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/kostkobv/mannequin/pkg"
+	"github.com/kostkobv/mannequin/pkg/kubectl"
+
+	"gopkg.in/yaml.v2"
+)
+
+// Labels the applied objects are tracked with.
+const (
+	LabelManagedBy = "app.kubernetes.io/managed-by"
+	LabelProject   = "mannequin/project"
+	LabelInstall   = "mannequin/install"
+	managedBy      = "mannequin"
+	fieldManager   = "mannequin"
+)
+
+// pruneKinds are always checked for the objects removed from the manifests
+// (along with the kinds of the current manifests).
+var pruneKinds = []string{
+	"deployment", "statefulset", "daemonset", "job", "cronjob", "service", "ingress",
+	"configmap", "secret", "serviceaccount", "persistentvolumeclaim",
+	"role", "rolebinding", "networkpolicy", "horizontalpodautoscaler", "poddisruptionbudget",
+}
+
+// Deployer applies the plain manifests with kubectl server-side apply.
+// Applied objects are labeled with the project and the installation, so the objects
+// removed from the manifests are pruned on the next deployment.
+type Deployer struct {
+	lc      LConfig
+	project string
+	install string
+}
+
+// NewDeployer is a constructor for Deployer.
+func NewDeployer(lc LConfig, t pkg.Target, project, install string) Deployer {
+	lc.Target = t
+	return Deployer{lc: lc, project: project, install: install}
+}
+
+// Check impl.
+func (d Deployer) Check() error {
+	if _, err := kubectl.CheckInstalled(); err != nil {
+		return fmt.Errorf("kubectl: %s", err)
+	}
+
+	return nil
+}
+
+// Lint impl.
+// Manifests are rendered, so broken files are reported before the deployment.
+func (d Deployer) Lint(w io.Writer, vars pkg.VarStorer) error {
+	_, err := d.render(vars)
+	return err
+}
+
+// Plan impl.
+func (d Deployer) Plan(w io.Writer, vars pkg.VarStorer) error {
+	manifests, err := d.render(vars)
+	if err != nil {
+		return err
+	}
+
+	fmt.Fprintln(w, "Manifests would be applied with:")
+	fmt.Fprintln(w, "----------------------------------------------------")
+	cmd := []string{"kubectl"}
+	if d.lc.Target.KubeContext != "" {
+		cmd = append(cmd, "--context", d.lc.Target.KubeContext)
+	}
+	fmt.Fprintln(w, pkg.Cmdline(append(cmd, d.applyArgs(vars)...)))
+	fmt.Fprintln(w, "----------------------------------------------------")
+	fmt.Fprintf(w, "Objects labeled %s that are not in the manifests would be pruned.\n", d.selector())
+	fmt.Fprintln(w, "Rendered manifests:")
+	fmt.Fprintln(w, "----------------------------------------------------")
+	fmt.Fprintf(w, "%s", manifests)
+	fmt.Fprintln(w, "----------------------------------------------------")
+
+	return nil
+}
+
+// Deploy impl.
+func (d Deployer) Deploy(w io.Writer, vars pkg.VarStorer) error {
+	manifests, err := d.render(vars)
+	if err != nil {
+		return err
+	}
+
+	out, err := kubectl.Run(d.lc.Target.KubeContext, manifests, d.applyArgs(vars)...)
+	if err != nil {
+		return fmt.Errorf("failed to apply manifests: %s", err)
+	}
+
+	fmt.Fprintln(w, "Deploying:")
+	fmt.Fprintln(w, "----------------------------------------------------")
+	fmt.Fprintf(w, "%s", out)
+	fmt.Fprintln(w, "----------------------------------------------------")
+
+	if err := d.prune(w, vars, manifests); err != nil {
+		return err
+	}
+
+	if d.lc.Target.Wait {
+		if err := kubectl.WaitRollout(w, d.lc.Target, vars.Replace(d.lc.Namespace), manifests); err != nil {
+			return err
+		}
+	}
+	fmt.Fprintln(w, "Successfully deployed!")
+
+	return nil
+}
+
+// Down impl.
+// Every object labeled with the project is deleted.
+func (d Deployer) Down(w io.Writer, vars pkg.VarStorer) error {
+	// manifests could be already removed, so the default kinds are enough.
+	manifests, _ := d.render(vars)
+
+	kinds, err := d.kinds(manifests)
+	if err != nil {
+		return err
+	}
+
+	out, err := kubectl.Run(d.lc.Target.KubeContext, nil, "delete", strings.Join(kinds, ","),
+		"--selector", d.selector(), "--all-namespaces", "--ignore-not-found")
+	if err != nil {
+		return fmt.Errorf("failed to delete objects: %s", err)
+	}
+
+	fmt.Fprintf(w, "%s", out)
+
+	return nil
+}
+
+func (d Deployer) applyArgs(vars pkg.VarStorer) []string {
+	args := []string{"apply", "--server-side", "--force-conflicts", "--field-manager", fieldManager}
+	if d.lc.Namespace != "" {
+		args = append(args, "--namespace", vars.Replace(d.lc.Namespace))
+	}
+
+	return append(args, "-f", "-")
+}
+
+func (d Deployer) selector() string {
+	return LabelProject + "=" + d.project + "," + LabelInstall + "=" + d.install
+}
+
+// prune deletes the objects of the project that are not in the manifests anymore.
+func (d Deployer) prune(w io.Writer, vars pkg.VarStorer, manifests []byte) error {
+	objs, err := kubectl.Objects(manifests)
+	if err != nil {
+		return err
+	}
+	kinds, err := d.kinds(manifests)
+	if err != nil {
+		return err
+	}
+
+	namespace, err := d.namespace(vars)
+	if err != nil {
+		return err
+	}
+
+	found, err := kubectl.Get(d.lc.Target.KubeContext, "", kinds, d.selector())
+	if err != nil {
+		return fmt.Errorf("couldn't list deployed objects: %s", err)
+	}
+
+	for _, o := range stale(objs, found, namespace) {
+		ns := o.Metadata.Namespace
+		ref := o.Ref()
+		args := []string{"delete", o.Ref(), "--ignore-not-found"}
+		if ns != "" {
+			ref = ns + "/" + ref
+			args = append(args, "--namespace", ns)
+		}
+		if _, err := kubectl.Run(d.lc.Target.KubeContext, nil, args...); err != nil {
+			return fmt.Errorf("couldn't prune %s: %s", ref, err)
+		}
+		fmt.Fprintf(w, "%s is pruned.\n", ref)
+	}
+
+	return nil
+}
+
+// stale returns the found objects that are not in the applied ones.
+// Objects are matched by the namespace and the reference. Applied objects without namespace
+// are applied to the provided one, unless they are cluster scoped, so they are matched by the reference as well.
+func stale(applied, found []kubectl.Object, namespace string) []kubectl.Object {
+	refs, unscoped := map[string]bool{}, map[string]bool{}
+	for _, o := range applied {
+		ns := o.Metadata.Namespace
+		if ns == "" {
+			ns = namespace
+			unscoped[o.Ref()] = true
+		}
+		refs[ns+"/"+o.Ref()] = true
+	}
+
+	var res []kubectl.Object
+	for _, o := range found {
+		ns := o.Metadata.Namespace
+		if refs[ns+"/"+o.Ref()] || (ns == "" && unscoped[o.Ref()]) {
+			continue
+		}
+		res = append(res, o)
+	}
+
+	return res
+}
+
+// namespace the objects without one are applied to:
+// the one of the configuration or the default one of the context.
+func (d Deployer) namespace(vars pkg.VarStorer) (string, error) {
+	if ns := vars.Replace(d.lc.Namespace); ns != "" {
+		return ns, nil
+	}
+
+	out, err := kubectl.Run(d.lc.Target.KubeContext, nil, "config", "view", "--minify", "--output", "jsonpath={..namespace}")
+	if err != nil {
+		return "", fmt.Errorf("couldn't get the namespace of the context: %s", err)
+	}
+	if ns := strings.TrimSpace(string(out)); ns != "" {
+		return ns, nil
+	}
+
+	return "default", nil
+}
+
+// kinds that are checked for the objects of the project.
+func (d Deployer) kinds(manifests []byte) ([]string, error) {
+	objs, err := kubectl.Objects(manifests)
+	if err != nil {
+		return nil, err
+	}
+
+	set := map[string]bool{}
+	for _, k := range pruneKinds {
+		set[k] = true
+	}
+	for _, o := range objs {
+		set[strings.ToLower(o.Kind)] = true
+	}
+
+	res := make([]string, 0, len(set))
+	for k := range set {
+		res = append(res, k)
+	}
+	sort.Strings(res)
+
+	return res, nil
+}
+
+// render reads the manifests of the dir, expands the variables and labels every object with the project.
+func (d Deployer) render(vars pkg.VarStorer) ([]byte, error) {
+	if err := d.lc.Validate(); err != nil {
+		return nil, err
+	}
+
+	dir := vars.Replace(d.lc.Path)
+	var files []string
+	err := filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+
+		switch filepath.Ext(path) {
+		case ".yaml", ".yml", ".json":
+			if !info.IsDir() {
+				files = append(files, path)
+			}
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("couldn't read manifests: %s", err)
+	}
+	if len(files) == 0 {
+		return nil, fmt.Errorf("no manifests are found in \"%s\"", dir)
+	}
+
+	var buf bytes.Buffer
+	for _, f := range files {
+		data, err := ioutil.ReadFile(f)
+		if err != nil {
+			return nil, err
+		}
+
+		dec := yaml.NewDecoder(strings.NewReader(vars.Replace(string(data))))
+		for {
+			var doc yaml.MapSlice
+			err := dec.Decode(&doc)
+			if err == io.EOF {
+				break
+			}
+			if err != nil {
+				return nil, fmt.Errorf("couldn't parse %s: %s", f, err)
+			}
+			if len(doc) == 0 {
+				continue
+			}
+
+			if doc, err = d.label(doc); err != nil {
+				return nil, fmt.Errorf("%s: %s", f, err)
+			}
+
+			out, err := yaml.Marshal(doc)
+			if err != nil {
+				return nil, err
+			}
+			buf.WriteString("---\n")
+			buf.Write(out)
+		}
+	}
+
+	return buf.Bytes(), nil
+}
+
+// label the object with the project.
+func (d Deployer) label(doc yaml.MapSlice) (yaml.MapSlice, error) {
+	kind, _ := value(doc, "kind").(string)
+	meta, _ := value(doc, "metadata").(yaml.MapSlice)
+	name, _ := value(meta, "name").(string)
+	if kind == "" || name == "" {
+		return nil, fmt.Errorf("object without kind or metadata.name is found")
+	}
+
+	labels, _ := value(meta, "labels").(yaml.MapSlice)
+	labels = set(labels, LabelManagedBy, managedBy)
+	labels = set(labels, LabelProject, d.project)
+	labels = set(labels, LabelInstall, d.install)
+	meta = set(meta, "labels", labels)
+
+	return set(doc, "metadata", meta), nil
+}
+
+func value(ms yaml.MapSlice, key string) interface{} {
+	for _, i := range ms {
+		if i.Key == key {
+			return i.Value
+		}
+	}
+
+	return nil
+}
+
+func set(ms yaml.MapSlice, key string, val interface{}) yaml.MapSlice {
+	for i := range ms {
+		if ms[i].Key == key {
+			ms[i].Value = val
+			return ms
+		}
+	}
+
+	return append(ms, yaml.MapItem{Key: key, Value: val})
+}
@@ -0,0 +1,210 @@
+package manifests
+
+import (
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/kostkobv/mannequin/pkg"
+	"github.com/kostkobv/mannequin/pkg/kubectl"
+
+	"gopkg.in/yaml.v2"
+)
+
+// testVars is the VarStorer of the tests.
+type testVars map[string]string
+
+func (tv testVars) Register(name, val string) error {
+	tv[name] = val
+	return nil
+}
+
+func (tv testVars) Replace(value string) string {
+	for k, v := range tv {
+		value = strings.Replace(value, "$"+k, v, -1)
+	}
+	return value
+}
+
+func (tv testVars) Var(name string) (string, error) {
+	if v, ok := tv[name]; ok {
+		return v, nil
+	}
+	return "", errors.New("variable is not set")
+}
+
+func TestLabel(t *testing.T) {
+	d := NewDeployer(LConfig{Path: "."}, pkg.Target{}, "app", "abc")
+
+	tests := []struct {
+		name string
+		doc  string
+		want map[string]string
+		err  bool
+	}{
+		{
+			name: "no labels",
+			doc:  "kind: ConfigMap\nmetadata:\n  name: cfg\n",
+			want: map[string]string{LabelManagedBy: managedBy, LabelProject: "app", LabelInstall: "abc"},
+		},
+		{
+			name: "own labels are kept",
+			doc:  "kind: ConfigMap\nmetadata:\n  name: cfg\n  labels:\n    tier: web\n    mannequin/project: other\n",
+			want: map[string]string{"tier": "web", LabelManagedBy: managedBy, LabelProject: "app", LabelInstall: "abc"},
+		},
+		{name: "no kind", doc: "metadata:\n  name: cfg\n", err: true},
+		{name: "no name", doc: "kind: ConfigMap\nmetadata:\n  labels: {}\n", err: true},
+		{name: "no metadata", doc: "kind: ConfigMap\n", err: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var doc yaml.MapSlice
+			if err := yaml.Unmarshal([]byte(tt.doc), &doc); err != nil {
+				t.Fatal(err)
+			}
+
+			res, err := d.label(doc)
+			if tt.err {
+				if err == nil {
+					t.Fatal("expected the object to be rejected")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			out, err := yaml.Marshal(res)
+			if err != nil {
+				t.Fatal(err)
+			}
+			objs, err := kubectl.Objects(out)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(objs) != 1 || !reflect.DeepEqual(objs[0].Metadata.Labels, tt.want) {
+				t.Errorf("expected labels %v, got %s", tt.want, out)
+			}
+		})
+	}
+}
+
+func TestRender(t *testing.T) {
+	dir, err := ioutil.TempDir("", "manifests")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	files := map[string]string{
+		"a.yaml":       "kind: Deployment\nmetadata:\n  name: app\nspec:\n  image: $IMAGE\n---\n---\nkind: Service\nmetadata:\n  name: app\n",
+		"nested/b.yml": "kind: ConfigMap\nmetadata:\n  name: cfg\n",
+		"ignored.txt":  "kind: Secret\n",
+		"empty/.keep":  "",
+	}
+	for name, body := range files {
+		path := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := ioutil.WriteFile(path, []byte(body), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	d := NewDeployer(LConfig{Path: dir}, pkg.Target{}, "app", "abc")
+	out, err := d.render(testVars{"IMAGE": "app:1"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	objs, err := kubectl.Objects(out)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var refs []string
+	for _, o := range objs {
+		refs = append(refs, o.Ref())
+		if o.Metadata.Labels[LabelInstall] != "abc" {
+			t.Errorf("%s is not labeled: %v", o.Ref(), o.Metadata.Labels)
+		}
+	}
+	if want := []string{"deployment/app", "service/app", "configmap/cfg"}; !reflect.DeepEqual(refs, want) {
+		t.Errorf("expected %v, got %v", want, refs)
+	}
+	if !strings.Contains(string(out), "image: app:1") {
+		t.Errorf("variables are not expanded:\n%s", out)
+	}
+
+	if err := ioutil.WriteFile(filepath.Join(dir, "broken.yaml"), []byte("metadata:\n  name: nameless\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := d.render(testVars{}); err == nil || !strings.Contains(err.Error(), "broken.yaml") {
+		t.Errorf("expected the object without kind to be rejected, got %v", err)
+	}
+}
+
+func TestStale(t *testing.T) {
+	obj := func(kind, name, ns string) kubectl.Object {
+		var o kubectl.Object
+		o.Kind, o.Metadata.Name, o.Metadata.Namespace = kind, name, ns
+		return o
+	}
+
+	tests := []struct {
+		name    string
+		applied []kubectl.Object
+		found   []kubectl.Object
+		want    []kubectl.Object
+	}{
+		{
+			name:    "namespaced objects",
+			applied: []kubectl.Object{obj("Deployment", "app", "dev")},
+			found:   []kubectl.Object{obj("Deployment", "app", "dev"), obj("Deployment", "old", "dev")},
+			want:    []kubectl.Object{obj("Deployment", "old", "dev")},
+		},
+		{
+			name:    "same object in the other namespace",
+			applied: []kubectl.Object{obj("Deployment", "app", "dev")},
+			found:   []kubectl.Object{obj("Deployment", "app", "dev"), obj("Deployment", "app", "prod")},
+			want:    []kubectl.Object{obj("Deployment", "app", "prod")},
+		},
+		{
+			name:    "objects of the default namespace",
+			applied: []kubectl.Object{obj("Service", "app", "")},
+			found:   []kubectl.Object{obj("Service", "app", "default"), obj("Service", "app", "other")},
+			want:    []kubectl.Object{obj("Service", "app", "other")},
+		},
+		{
+			name:    "cluster scoped objects",
+			applied: []kubectl.Object{obj("ClusterRole", "app", "")},
+			found:   []kubectl.Object{obj("ClusterRole", "app", ""), obj("ClusterRole", "old", "")},
+			want:    []kubectl.Object{obj("ClusterRole", "old", "")},
+		},
+		{
+			name:    "kinds differ",
+			applied: []kubectl.Object{obj("Service", "app", "default")},
+			found:   []kubectl.Object{obj("Deployment", "app", "default")},
+			want:    []kubectl.Object{obj("Deployment", "app", "default")},
+		},
+		{
+			name:    "nothing is applied",
+			applied: nil,
+			found:   []kubectl.Object{obj("Deployment", "app", "default")},
+			want:    []kubectl.Object{obj("Deployment", "app", "default")},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := stale(tt.applied, tt.found, "default"); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("expected %v, got %v", tt.want, got)
+			}
+		})
+	}
+}
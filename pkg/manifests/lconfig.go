@@ -0,0 +1,36 @@
+package manifests
+
+import (
+	"errors"
+
+	"github.com/kostkobv/mannequin/pkg"
+)
+
+// LConfig of the plain manifests deployment.
+// Path is the dir with the manifests (*.yaml, *.yml, *.json), variables are expanded in them.
+type LConfig struct {
+	Path      string `yaml:"path,omitempty,flow"`
+	Namespace string `yaml:"namespace,omitempty,flow"`
+
+	// Target is used by every command that talks to the cluster.
+	Target pkg.Target `yaml:"-"`
+}
+
+// Validate the LConfig.
+func (lc *LConfig) Validate() error {
+	if lc.Path == "" {
+		return errors.New("manifests path is required")
+	}
+
+	return nil
+}
+
+// Merge overrides the LConfig with the set fields of o.
+func (lc *LConfig) Merge(o LConfig) {
+	if o.Path != "" {
+		lc.Path = o.Path
+	}
+	if o.Namespace != "" {
+		lc.Namespace = o.Namespace
+	}
+}
@@ -0,0 +1,75 @@
+package pkg
+
+import (
+	"bytes"
+	"io"
+	"sort"
+)
+
+// Mask replaces the secret values in the output.
+const Mask = "******"
+
+// minMaskLen of the secret value: shorter values (like `1` or `80`) would garble
+// the unrelated parts of the output, so they are not masked.
+const minMaskLen = 4
+
+// MaskWriter masks the secret values in everything that is written to the underlying writer.
+// Output is written line by line, so the values split between the writes are masked too.
+// Flush has to be called once everything is written.
+type MaskWriter struct {
+	w       io.Writer
+	secrets [][]byte
+	buf     []byte
+}
+
+// NewMaskWriter is a constructor for MaskWriter.
+// Values shorter than 4 characters are not masked.
+func NewMaskWriter(w io.Writer, secrets []string) *MaskWriter {
+	mw := &MaskWriter{w: w}
+	for _, s := range secrets {
+		if len(s) >= minMaskLen {
+			mw.secrets = append(mw.secrets, []byte(s))
+		}
+	}
+	// longer values are masked first, so the values that contain the others are masked completely.
+	sort.Slice(mw.secrets, func(i, j int) bool { return len(mw.secrets[i]) > len(mw.secrets[j]) })
+
+	return mw
+}
+
+// Write impl.
+func (mw *MaskWriter) Write(p []byte) (int, error) {
+	mw.buf = append(mw.buf, p...)
+
+	i := bytes.LastIndexByte(mw.buf, '\n')
+	if i < 0 {
+		return len(p), nil
+	}
+
+	if _, err := mw.w.Write(mw.mask(mw.buf[:i+1])); err != nil {
+		return 0, err
+	}
+	mw.buf = append(mw.buf[:0], mw.buf[i+1:]...)
+
+	return len(p), nil
+}
+
+// Flush writes the rest of the output.
+func (mw *MaskWriter) Flush() error {
+	if len(mw.buf) == 0 {
+		return nil
+	}
+
+	_, err := mw.w.Write(mw.mask(mw.buf))
+	mw.buf = mw.buf[:0]
+
+	return err
+}
+
+func (mw *MaskWriter) mask(p []byte) []byte {
+	for _, s := range mw.secrets {
+		p = bytes.Replace(p, s, []byte(Mask), -1)
+	}
+
+	return p
+}
@@ -0,0 +1,59 @@
+package pkg
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestMaskWriter(t *testing.T) {
+	tests := []struct {
+		name    string
+		secrets []string
+		writes  []string
+		want    string
+	}{
+		{
+			name:    "secret value",
+			secrets: []string{"s3cr3t"},
+			writes:  []string{"password: s3cr3t\n"},
+			want:    "password: ******\n",
+		},
+		{
+			name:    "short values are kept",
+			secrets: []string{"1", "80", "yes", ""},
+			writes:  []string{"replicas: 1\nport: 8080\nenabled: yes\n"},
+			want:    "replicas: 1\nport: 8080\nenabled: yes\n",
+		},
+		{
+			name:    "longer values first",
+			secrets: []string{"pass", "password1"},
+			writes:  []string{"a: password1, b: pass\n"},
+			want:    "a: ******, b: ******\n",
+		},
+		{
+			name:    "value split between the writes",
+			secrets: []string{"s3cr3t"},
+			writes:  []string{"password: s3c", "r3t\nnext: s3", "cr3t"},
+			want:    "password: ******\nnext: ******",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			mw := NewMaskWriter(&buf, tt.secrets)
+			for _, w := range tt.writes {
+				if _, err := mw.Write([]byte(w)); err != nil {
+					t.Fatal(err)
+				}
+			}
+			if err := mw.Flush(); err != nil {
+				t.Fatal(err)
+			}
+
+			if got := buf.String(); got != tt.want {
+				t.Errorf("expected %q, got %q", tt.want, got)
+			}
+		})
+	}
+}
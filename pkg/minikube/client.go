@@ -1,48 +1,147 @@
 package minikube
 
 import (
+	"encoding/json"
 	"errors"
 	"fmt"
 	"os/exec"
-	"regexp"
+	"strings"
 )
 
-var (
-	checkVer = regexp.MustCompile(`minikube version: (v\d+.\d+.\d+)`)
-	checkRun = regexp.MustCompile(`host: Running\nkubelet: Running\napiserver: Running\nkubectl: Correctly Configured: pointing to minikube-vm at.*`)
+// DefaultProfile of minikube.
+const DefaultProfile = "minikube"
+
+// states of the components as printed by `minikube status`.
+const (
+	running    = "Running"
+	configured = "Configured"
+	// irrelevant components are the ones the node doesn't have (e.g. apiserver of the worker).
+	irrelevant = "Irrelevant"
 )
 
+// Status of the minikube profile (node) as printed by `minikube status -o json`.
+type Status struct {
+	Name       string `json:"Name"`
+	Host       string `json:"Host"`
+	Kubelet    string `json:"Kubelet"`
+	APIServer  string `json:"APIServer"`
+	Kubeconfig string `json:"Kubeconfig"`
+}
+
+// CheckInstalled returns the version of minikube.
 func CheckInstalled() (string, error) {
-	cmd := exec.Command("minikube", "version")
-	out, err := cmd.Output()
+	out, err := exec.Command("minikube", "version", "--output", "json").Output()
 	if err != nil {
-		return "", err
+		if _, ok := err.(*exec.ExitError); !ok {
+			return "", errors.New("minikube is not installed")
+		}
+		return "", fmt.Errorf("couldn't get minikube version: %s", err)
 	}
 
-	res := checkVer.FindStringSubmatch(string(out))
-	if len(res) < 1 {
-		return "", errors.New("minikube is not installed")
+	var ver struct {
+		MinikubeVersion string `json:"minikubeVersion"`
+	}
+	if err := json.Unmarshal(out, &ver); err != nil {
+		return "", fmt.Errorf("couldn't parse minikube version: %s", err)
+	}
+	if ver.MinikubeVersion == "" {
+		return "", errors.New("minikube version is not found")
 	}
 
-	return res[1], nil
+	return ver.MinikubeVersion, nil
 }
 
-func CheckRunning() error {
-	cmd := exec.Command("minikube", "status")
-	out, err := cmd.Output()
+// CheckRunning checks if every component of the profile is running.
+// DefaultProfile is checked if profile is not provided.
+func CheckRunning(profile string) error {
+	if profile == "" {
+		profile = DefaultProfile
+	}
+
+	sts, err := GetStatus(profile)
+	if err != nil {
+		return err
+	}
+
+	for _, st := range sts {
+		if err := st.Check(); err != nil {
+			hint := "minikube start --profile " + profile
+			if st.running() {
+				hint = "minikube update-context --profile " + profile
+			}
+
+			return fmt.Errorf("profile \"%s\": %s (run `%s`)", profile, err, hint)
+		}
+	}
+
+	return nil
+}
+
+// GetStatus of every node of the profile.
+func GetStatus(profile string) ([]Status, error) {
+	// minikube exits with non-zero code if anything is not running,
+	// but the status is printed anyway.
+	out, err := exec.Command("minikube", "status", "--profile", profile, "--output", "json").Output()
 	if err != nil {
 		ee, ok := err.(*exec.ExitError)
 		if !ok {
-			return err
+			return nil, errors.New("minikube is not installed")
 		}
+		if len(strings.TrimSpace(string(out))) == 0 {
+			return nil, fmt.Errorf("profile \"%s\" is not found: %s (error code %d)",
+				profile, strings.TrimSpace(string(ee.Stderr)), ee.ExitCode())
+		}
+	}
 
-		return fmt.Errorf("check if minikube is running: %s (error code %d)", ee.Stderr, ee.ExitCode())
+	// multi-node profiles are printed as a list.
+	var sts []Status
+	if err := json.Unmarshal(out, &sts); err != nil {
+		var st Status
+		if err := json.Unmarshal(out, &st); err != nil {
+			return nil, fmt.Errorf("couldn't parse minikube status: %s", err)
+		}
+		sts = []Status{st}
 	}
 
-	res := checkRun.FindStringSubmatch(string(out))
-	if len(res) < 1 {
-		return errors.New("check if minikube is running")
+	return sts, nil
+}
+
+// Check if every component of the node is running.
+// Error names the component that is down.
+func (s Status) Check() error {
+	components := []struct {
+		name  string
+		state string
+	}{
+		{"host", s.Host},
+		{"kubelet", s.Kubelet},
+		{"apiserver", s.APIServer},
+	}
+
+	for _, c := range components {
+		// workers have no apiserver.
+		if c.name == "apiserver" && (c.state == "" || c.state == irrelevant) {
+			continue
+		}
+		if c.state != running {
+			state := c.state
+			if state == "" {
+				state = "unknown"
+			}
+			return fmt.Errorf("%s of node \"%s\" is %s", c.name, s.Name, state)
+		}
+	}
+
+	switch s.Kubeconfig {
+	case "", configured, irrelevant:
+	default:
+		return fmt.Errorf("kubeconfig of node \"%s\" is %s", s.Name, s.Kubeconfig)
 	}
 
 	return nil
 }
+
+func (s Status) running() bool {
+	apiserver := s.APIServer == running || s.APIServer == "" || s.APIServer == irrelevant
+	return s.Host == running && s.Kubelet == running && apiserver
+}
@@ -0,0 +1,18 @@
+package minikube
+
+// LConfig of minikube.
+// Profile is DefaultProfile if not set.
+type LConfig struct {
+	Profile string `yaml:"profile,omitempty,flow"`
+}
+
+// IsContext returns true if kubernetes context is the one of the profile.
+// Minikube names the context after the profile.
+func (lc LConfig) IsContext(k8sCtx string) bool {
+	profile := lc.Profile
+	if profile == "" {
+		profile = DefaultProfile
+	}
+
+	return k8sCtx == profile
+}
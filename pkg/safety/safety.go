@@ -0,0 +1,112 @@
+package safety
+
+import (
+	"net"
+	"net/url"
+	"strings"
+)
+
+// Class of the kubernetes context.
+type Class string
+
+// Available Classes.
+const (
+	Local  Class = "local"
+	Remote Class = "remote"
+)
+
+// names of the contexts created by the local kubernetes distributions.
+var localNames = []string{"minikube", "docker-desktop", "docker-for-desktop"}
+
+// prefixes of the contexts created by the local kubernetes distributions.
+var localPrefixes = []string{"kind-", "k3d-"}
+
+// hosts that are resolved to the local machine.
+var localHosts = []string{"localhost", "host.docker.internal", "kubernetes.docker.internal"}
+
+// private networks the local distributions (minikube, kind, docker) run the API server in.
+var privateNets = []string{"10.0.0.0/8", "172.16.0.0/12", "192.168.0.0/16", "fc00::/7"}
+
+// Classify the context by it's name and API server address.
+// Names of the local distributions (and the provided local contexts, e.g. of the named minikube profiles)
+// are trusted only if the API server is in the private network as well, since anyone could name the context that way.
+func Classify(k8sCtx, server string, localCtxs ...string) Class {
+	if IsLocalServer(server) {
+		return Local
+	}
+
+	if !(isLocalName(k8sCtx) || Allowed(k8sCtx, localCtxs)) || !isPrivateServer(server) {
+		return Remote
+	}
+
+	return Local
+}
+
+func isLocalName(k8sCtx string) bool {
+	for _, n := range localNames {
+		if k8sCtx == n {
+			return true
+		}
+	}
+
+	for _, p := range localPrefixes {
+		if strings.HasPrefix(k8sCtx, p) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// IsLocalServer returns true if API server address points to the local machine.
+func IsLocalServer(server string) bool {
+	host := serverHost(server)
+	if host == "" {
+		return false
+	}
+
+	for _, h := range localHosts {
+		if host == h {
+			return true
+		}
+	}
+
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
+
+// isPrivateServer returns true if API server address is in the private network.
+func isPrivateServer(server string) bool {
+	ip := net.ParseIP(serverHost(server))
+	if ip == nil {
+		return false
+	}
+
+	for _, n := range privateNets {
+		if _, ipnet, err := net.ParseCIDR(n); err == nil && ipnet.Contains(ip) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func serverHost(server string) string {
+	u, err := url.Parse(server)
+	if err != nil {
+		return ""
+	}
+
+	return u.Hostname()
+}
+
+// Allowed returns true if context is in the list.
+func Allowed(k8sCtx string, allowed []string) bool {
+	for _, a := range allowed {
+		if a == k8sCtx {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,39 @@
+package safety
+
+import "testing"
+
+func TestClassify(t *testing.T) {
+	tests := []struct {
+		k8sCtx    string
+		server    string
+		localCtxs []string
+		want      Class
+	}{
+		{k8sCtx: "anything", server: "https://127.0.0.1:6443", want: Local},
+		{k8sCtx: "anything", server: "https://[::1]:6443", want: Local},
+		{k8sCtx: "anything", server: "https://localhost:6443", want: Local},
+		{k8sCtx: "docker-desktop", server: "https://kubernetes.docker.internal:6443", want: Local},
+		{k8sCtx: "minikube", server: "https://192.168.49.2:8443", want: Local},
+		{k8sCtx: "kind-dev", server: "https://172.18.0.2:6443", want: Local},
+		{k8sCtx: "k3d-dev", server: "https://10.0.0.5:6443", want: Local},
+		{k8sCtx: "minikube", server: "https://[fd00::2]:8443", want: Local},
+		// local names are not trusted for the public servers.
+		{k8sCtx: "minikube", server: "https://35.1.2.3", want: Remote},
+		{k8sCtx: "kind-prod", server: "https://prod.example.com", want: Remote},
+		{k8sCtx: "minikube", server: "", want: Remote},
+		// private servers are not trusted for the other names.
+		{k8sCtx: "prod", server: "https://10.0.0.1:6443", want: Remote},
+		{k8sCtx: "prod", server: "https://prod.example.com", want: Remote},
+		{k8sCtx: "minikube-prod", server: "https://192.168.1.2", want: Remote},
+		// contexts of the named minikube profiles.
+		{k8sCtx: "dev", server: "https://192.168.49.2:8443", localCtxs: []string{"dev"}, want: Local},
+		{k8sCtx: "dev", server: "https://35.1.2.3", localCtxs: []string{"dev"}, want: Remote},
+		{k8sCtx: "prod", server: "https://192.168.49.2:8443", localCtxs: []string{"dev"}, want: Remote},
+	}
+
+	for _, tt := range tests {
+		if got := Classify(tt.k8sCtx, tt.server, tt.localCtxs...); got != tt.want {
+			t.Errorf("%s (%s): expected %s, got %s", tt.k8sCtx, tt.server, tt.want, got)
+		}
+	}
+}
@@ -0,0 +1,276 @@
+package scaffold
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// Lang of the project.
+type Lang string
+
+// Supported languages.
+const (
+	Go     Lang = "go"
+	Node   Lang = "node"
+	Python Lang = "python"
+	Java   Lang = "java"
+)
+
+// DefaultPort the generated application is expected to listen on.
+const DefaultPort = 8080
+
+// markers of the languages in the order they are checked.
+var markers = []struct {
+	file string
+	lang Lang
+}{
+	{"go.mod", Go},
+	{"package.json", Node},
+	{"requirements.txt", Python},
+	{"pom.xml", Java},
+}
+
+// Detect the language of the project in the dir by it's dependency manifest.
+func Detect(dir string) (Lang, error) {
+	for _, m := range markers {
+		if _, err := os.Stat(filepath.Join(dir, m.file)); err == nil {
+			return m.lang, nil
+		}
+	}
+
+	files := make([]string, len(markers))
+	for i, m := range markers {
+		files[i] = m.file
+	}
+
+	return "", fmt.Errorf("couldn't detect project language (none of %s is found)", strings.Join(files, ", "))
+}
+
+// Dockerfile writes multi-stage Dockerfile for the language to the path.
+// Existing file is never overwritten.
+func Dockerfile(path string, l Lang) error {
+	tmpl, ok := dockerfiles[l]
+	if !ok {
+		return fmt.Errorf("language \"%s\" is not supported", l)
+	}
+
+	return write(path, strings.Replace(tmpl, "<port>", strconv.Itoa(DefaultPort), -1))
+}
+
+// Chart writes minimal helm chart with deployment, service and optional ingress to the dir.
+// Image repository and tag are expected to be set on deploy.
+// Existing chart is never overwritten.
+func Chart(dir, name string, ingress bool) error {
+	if name == "" {
+		return errors.New("chart name is required")
+	}
+	if _, err := os.Stat(dir); err == nil {
+		return fmt.Errorf("\"%s\" already exists", dir)
+	}
+
+	r := strings.NewReplacer(
+		"<name>", name,
+		"<port>", strconv.Itoa(DefaultPort),
+		"<ingress>", strconv.FormatBool(ingress),
+	)
+	for _, f := range chartFiles {
+		if err := write(filepath.Join(dir, f.path), r.Replace(f.body)); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func write(path, body string) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0644)
+	if err != nil {
+		return err
+	}
+
+	if _, err := f.WriteString(body); err != nil {
+		f.Close() // nolint: errcheck
+		return err
+	}
+
+	return f.Close()
+}
+
+var dockerfiles = map[Lang]string{
+	Go: `FROM golang:1.13-alpine AS build
+WORKDIR /src
+COPY go.mod go.sum* ./
+RUN go mod download
+COPY . .
+# adjust the path to the main package if it's not in the root
+RUN CGO_ENABLED=0 go build -o /bin/app .
+
+FROM alpine:3.11
+RUN apk add --no-cache ca-certificates
+COPY --from=build /bin/app /bin/app
+EXPOSE <port>
+ENTRYPOINT ["/bin/app"]
+`,
+	Node: `FROM node:12-alpine AS build
+WORKDIR /app
+COPY package*.json ./
+RUN npm ci
+COPY . .
+RUN npm run build --if-present && npm prune --production
+
+FROM node:12-alpine
+WORKDIR /app
+COPY --from=build /app .
+ENV PORT=<port>
+EXPOSE <port>
+CMD ["npm", "start"]
+`,
+	Python: `FROM python:3.8-slim AS build
+WORKDIR /app
+COPY requirements.txt .
+RUN pip install --no-cache-dir --prefix=/install -r requirements.txt
+
+FROM python:3.8-slim
+WORKDIR /app
+COPY --from=build /install /usr/local
+COPY . .
+ENV PORT=<port>
+EXPOSE <port>
+# adjust the entrypoint of the application
+CMD ["python", "main.py"]
+`,
+	Java: `FROM maven:3-jdk-11 AS build
+WORKDIR /src
+COPY pom.xml .
+RUN mvn -B dependency:go-offline
+COPY src ./src
+RUN mvn -B package -DskipTests
+
+FROM openjdk:11-jre-slim
+COPY --from=build /src/target/*.jar /app.jar
+EXPOSE <port>
+ENTRYPOINT ["java", "-jar", "/app.jar"]
+`,
+}
+
+var chartFiles = []struct {
+	path string
+	body string
+}{
+	{"Chart.yaml", `apiVersion: v1
+name: <name>
+description: Helm chart of <name>
+version: 0.1.0
+appVersion: "0.1.0"
+`},
+	{"values.yaml", `replicaCount: 1
+
+# repository and tag are set on deploy.
+image:
+  repository: <name>
+  tag: latest
+  pullPolicy: IfNotPresent
+
+service:
+  type: ClusterIP
+  port: 80
+  targetPort: <port>
+
+ingress:
+  enabled: <ingress>
+  host: <name>.local
+  path: /
+
+env: {}
+
+resources: {}
+`},
+	{".helmignore", `.git/
+*.swp
+*.bak
+*.tmp
+`},
+	{"templates/deployment.yaml", `apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: {{ .Release.Name }}
+  labels:
+    app.kubernetes.io/name: {{ .Chart.Name }}
+    app.kubernetes.io/instance: {{ .Release.Name }}
+spec:
+  replicas: {{ .Values.replicaCount }}
+  selector:
+    matchLabels:
+      app.kubernetes.io/name: {{ .Chart.Name }}
+      app.kubernetes.io/instance: {{ .Release.Name }}
+  template:
+    metadata:
+      labels:
+        app.kubernetes.io/name: {{ .Chart.Name }}
+        app.kubernetes.io/instance: {{ .Release.Name }}
+    spec:
+      containers:
+        - name: {{ .Chart.Name }}
+          image: "{{ .Values.image.repository }}:{{ .Values.image.tag }}"
+          imagePullPolicy: {{ .Values.image.pullPolicy }}
+          ports:
+            - name: http
+              containerPort: {{ .Values.service.targetPort }}
+          {{- with .Values.env }}
+          env:
+            {{- range $name, $value := . }}
+            - name: {{ $name }}
+              value: {{ $value | quote }}
+            {{- end }}
+          {{- end }}
+          resources:
+            {{- toYaml .Values.resources | nindent 12 }}
+`},
+	{"templates/service.yaml", `apiVersion: v1
+kind: Service
+metadata:
+  name: {{ .Release.Name }}
+  labels:
+    app.kubernetes.io/name: {{ .Chart.Name }}
+    app.kubernetes.io/instance: {{ .Release.Name }}
+spec:
+  type: {{ .Values.service.type }}
+  ports:
+    - port: {{ .Values.service.port }}
+      targetPort: http
+      name: http
+  selector:
+    app.kubernetes.io/name: {{ .Chart.Name }}
+    app.kubernetes.io/instance: {{ .Release.Name }}
+`},
+	{"templates/ingress.yaml", `{{- if .Values.ingress.enabled -}}
+apiVersion: networking.k8s.io/v1
+kind: Ingress
+metadata:
+  name: {{ .Release.Name }}
+  labels:
+    app.kubernetes.io/name: {{ .Chart.Name }}
+    app.kubernetes.io/instance: {{ .Release.Name }}
+spec:
+  rules:
+    - host: {{ .Values.ingress.host }}
+      http:
+        paths:
+          - path: {{ .Values.ingress.path }}
+            pathType: Prefix
+            backend:
+              service:
+                name: {{ .Release.Name }}
+                port:
+                  name: http
+{{- end }}
+`},
+}
@@ -0,0 +1,376 @@
+package secrets
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"regexp"
+	"strconv"
+
+	"gopkg.in/yaml.v2"
+)
+
+const (
+	keySize  = 32
+	saltSize = 16
+
+	// kdf the passphrase is turned into the key with.
+	kdf           = "pbkdf2-sha256"
+	kdfIterations = 600000
+)
+
+// types of the encrypted values, so they are restored as they were.
+const (
+	typeStr   = "str"
+	typeInt   = "int"
+	typeFloat = "float"
+	typeBool  = "bool"
+)
+
+// encrypted value template (sops alike): ENC[AES256_GCM,data:<base64>,iv:<base64>,type:<type>].
+// Values without the type are strings.
+var encVal = regexp.MustCompile(`^ENC\[AES256_GCM,data:([A-Za-z0-9+/=]*),iv:([A-Za-z0-9+/=]+)(?:,type:(str|int|float|bool))?\]$`)
+
+// Key is used to encrypt and decrypt the secret values.
+type Key struct {
+	aead cipher.AEAD
+}
+
+// keyFile is the stored Key: random data key encrypted with the one derived from the passphrase.
+type keyFile struct {
+	KDF        string `yaml:"kdf"`
+	Iterations int    `yaml:"iterations"`
+	Salt       string `yaml:"salt"`
+	Key        string `yaml:"key"`
+}
+
+// GenerateKey creates a new random Key and stores it to the provided path
+// encrypted with the passphrase. Fails if the file already exists.
+func GenerateKey(path, passphrase string) (*Key, error) {
+	if passphrase == "" {
+		return nil, errors.New("passphrase is required")
+	}
+
+	raw := make([]byte, keySize)
+	if _, err := rand.Read(raw); err != nil {
+		return nil, fmt.Errorf("couldn't generate key: %s", err)
+	}
+	salt := make([]byte, saltSize)
+	if _, err := rand.Read(salt); err != nil {
+		return nil, fmt.Errorf("couldn't generate salt: %s", err)
+	}
+
+	kek, err := newKey(pbkdf2([]byte(passphrase), salt, kdfIterations, keySize))
+	if err != nil {
+		return nil, err
+	}
+	wrapped, err := kek.seal(raw, typeStr)
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := yaml.Marshal(keyFile{
+		KDF:        kdf,
+		Iterations: kdfIterations,
+		Salt:       base64.StdEncoding.EncodeToString(salt),
+		Key:        wrapped,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0600)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	if _, err := f.Write(data); err != nil {
+		return nil, err
+	}
+
+	return newKey(raw)
+}
+
+// LoadKey reads the Key from the provided path and decrypts it with the passphrase.
+func LoadKey(path, passphrase string) (*Key, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var kf keyFile
+	if err := yaml.Unmarshal(data, &kf); err != nil {
+		return nil, fmt.Errorf("key is malformed: %s", err)
+	}
+	if kf.KDF != kdf || kf.Iterations <= 0 {
+		return nil, fmt.Errorf("key is malformed: unsupported kdf \"%s\"", kf.KDF)
+	}
+
+	salt, err := base64.StdEncoding.DecodeString(kf.Salt)
+	if err != nil || len(salt) == 0 {
+		return nil, errors.New("key is malformed: salt is missing")
+	}
+
+	kek, err := newKey(pbkdf2([]byte(passphrase), salt, kf.Iterations, keySize))
+	if err != nil {
+		return nil, err
+	}
+	raw, _, err := kek.open(kf.Key)
+	if err != nil {
+		return nil, errors.New("couldn't decrypt key: wrong passphrase or key is corrupted")
+	}
+
+	return newKey(raw)
+}
+
+func newKey(raw []byte) (*Key, error) {
+	if len(raw) != keySize {
+		return nil, fmt.Errorf("key is expected to be %d bytes long", keySize)
+	}
+
+	b, err := aes.NewCipher(raw)
+	if err != nil {
+		return nil, err
+	}
+
+	aead, err := cipher.NewGCM(b)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Key{aead: aead}, nil
+}
+
+// IsEncrypted returns true if the value is encrypted.
+func IsEncrypted(val string) bool {
+	return encVal.MatchString(val)
+}
+
+// Encrypt the value.
+func (k *Key) Encrypt(val string) (string, error) {
+	if k == nil {
+		return "", errors.New("key is required")
+	}
+
+	return k.seal([]byte(val), typeStr)
+}
+
+// Decrypt the value.
+// Fails if the value is not encrypted.
+func (k *Key) Decrypt(val string) (string, error) {
+	if k == nil {
+		return "", errors.New("key is required")
+	}
+
+	plain, _, err := k.open(val)
+	if err != nil {
+		return "", err
+	}
+
+	return string(plain), nil
+}
+
+func (k *Key) seal(plain []byte, typ string) (string, error) {
+	iv := make([]byte, k.aead.NonceSize())
+	if _, err := rand.Read(iv); err != nil {
+		return "", err
+	}
+
+	// type is authenticated as well, so it couldn't be swapped.
+	data := k.aead.Seal(nil, iv, plain, []byte(typ))
+
+	return fmt.Sprintf("ENC[AES256_GCM,data:%s,iv:%s,type:%s]",
+		base64.StdEncoding.EncodeToString(data),
+		base64.StdEncoding.EncodeToString(iv),
+		typ,
+	), nil
+}
+
+func (k *Key) open(val string) ([]byte, string, error) {
+	res := encVal.FindStringSubmatch(val)
+	if len(res) < 4 {
+		return nil, "", errors.New("value is not encrypted")
+	}
+
+	data, err := base64.StdEncoding.DecodeString(res[1])
+	if err != nil {
+		return nil, "", fmt.Errorf("value is malformed: %s", err)
+	}
+	iv, err := base64.StdEncoding.DecodeString(res[2])
+	if err != nil {
+		return nil, "", fmt.Errorf("value is malformed: %s", err)
+	}
+	if len(iv) != k.aead.NonceSize() {
+		return nil, "", errors.New("value is malformed: unexpected iv size")
+	}
+
+	// values encrypted without the type are strings with no additional data.
+	typ, ad := res[3], []byte(res[3])
+	if typ == "" {
+		typ, ad = typeStr, nil
+	}
+
+	plain, err := k.aead.Open(nil, iv, data, ad)
+	if err != nil {
+		return nil, "", errors.New("couldn't decrypt value: wrong key or value is corrupted")
+	}
+
+	return plain, typ, nil
+}
+
+// EncryptValues encrypts every scalar value of the yaml document keeping its type.
+// Keys, empty and already encrypted values are left as is.
+func (k *Key) EncryptValues(doc []byte) ([]byte, error) {
+	return k.walkValues(doc, k.encryptScalar)
+}
+
+// DecryptValues decrypts every value of the yaml document.
+// Fails if any of the values is not encrypted.
+func (k *Key) DecryptValues(doc []byte) ([]byte, error) {
+	return k.walkValues(doc, func(v interface{}) (interface{}, error) {
+		return k.decryptScalar(v, false)
+	})
+}
+
+// OpenValues decrypts the values of the yaml document for editing.
+// Values that are not encrypted are left as is, so they are encrypted on save.
+func (k *Key) OpenValues(doc []byte) ([]byte, error) {
+	return k.walkValues(doc, func(v interface{}) (interface{}, error) {
+		return k.decryptScalar(v, true)
+	})
+}
+
+func (k *Key) encryptScalar(v interface{}) (interface{}, error) {
+	switch vv := v.(type) {
+	case nil:
+		return nil, nil
+	case string:
+		if IsEncrypted(vv) {
+			return vv, nil
+		}
+		return k.seal([]byte(vv), typeStr)
+	case bool:
+		return k.seal([]byte(strconv.FormatBool(vv)), typeBool)
+	case int:
+		return k.seal([]byte(strconv.Itoa(vv)), typeInt)
+	case int64:
+		return k.seal([]byte(strconv.FormatInt(vv, 10)), typeInt)
+	case uint64:
+		return k.seal([]byte(strconv.FormatUint(vv, 10)), typeInt)
+	case float64:
+		return k.seal([]byte(strconv.FormatFloat(vv, 'g', -1, 64)), typeFloat)
+	}
+
+	return nil, fmt.Errorf("unsupported value type %T", v)
+}
+
+func (k *Key) decryptScalar(v interface{}, allowPlain bool) (interface{}, error) {
+	if v == nil {
+		return nil, nil
+	}
+
+	s, ok := v.(string)
+	if !ok || !IsEncrypted(s) {
+		if allowPlain {
+			return v, nil
+		}
+		return nil, errors.New("value is not encrypted")
+	}
+
+	plain, typ, err := k.open(s)
+	if err != nil {
+		return nil, err
+	}
+
+	return restore(string(plain), typ)
+}
+
+// restore the decrypted value to its type.
+func restore(plain, typ string) (interface{}, error) {
+	switch typ {
+	case typeInt:
+		if i, err := strconv.ParseInt(plain, 10, 64); err == nil {
+			if int64(int(i)) == i {
+				return int(i), nil
+			}
+			return i, nil
+		}
+		u, err := strconv.ParseUint(plain, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("value is malformed: %s", err)
+		}
+		return u, nil
+	case typeFloat:
+		f, err := strconv.ParseFloat(plain, 64)
+		if err != nil {
+			return nil, fmt.Errorf("value is malformed: %s", err)
+		}
+		return f, nil
+	case typeBool:
+		b, err := strconv.ParseBool(plain)
+		if err != nil {
+			return nil, fmt.Errorf("value is malformed: %s", err)
+		}
+		return b, nil
+	}
+
+	return plain, nil
+}
+
+func (k *Key) walkValues(doc []byte, fn func(interface{}) (interface{}, error)) ([]byte, error) {
+	if k == nil {
+		return nil, errors.New("key is required")
+	}
+
+	var tree yaml.MapSlice
+	if err := yaml.Unmarshal(doc, &tree); err != nil {
+		return nil, err
+	}
+
+	res, err := walk(tree, fn)
+	if err != nil {
+		return nil, err
+	}
+
+	return yaml.Marshal(res)
+}
+
+// walk the yaml tree applying fn to every scalar value.
+func walk(v interface{}, fn func(interface{}) (interface{}, error)) (interface{}, error) {
+	switch vv := v.(type) {
+	case yaml.MapSlice:
+		for i := range vv {
+			val, err := walk(vv[i].Value, fn)
+			if err != nil {
+				return nil, fmt.Errorf("%v: %s", vv[i].Key, err)
+			}
+			vv[i].Value = val
+		}
+	case map[interface{}]interface{}:
+		for key, item := range vv {
+			val, err := walk(item, fn)
+			if err != nil {
+				return nil, fmt.Errorf("%v: %s", key, err)
+			}
+			vv[key] = val
+		}
+	case []interface{}:
+		for i := range vv {
+			val, err := walk(vv[i], fn)
+			if err != nil {
+				return nil, fmt.Errorf("[%d]: %s", i, err)
+			}
+			vv[i] = val
+		}
+	default:
+		return fn(v)
+	}
+
+	return v, nil
+}
@@ -0,0 +1,219 @@
+package secrets
+
+import (
+	"encoding/base64"
+	"encoding/hex"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"gopkg.in/yaml.v2"
+)
+
+func testKey(t *testing.T) *Key {
+	t.Helper()
+
+	raw := make([]byte, keySize)
+	for i := range raw {
+		raw[i] = byte(i)
+	}
+	k, err := newKey(raw)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return k
+}
+
+func TestPBKDF2(t *testing.T) {
+	// RFC 7914 test vector of PBKDF2-HMAC-SHA256.
+	want := "55ac046e56e3089fec1691c22544b605f94185216dde0465e68b9d57c20dacbc49ca9cccf179b645991664b39d77ef317c71b845b1e30bd509112041d3a19783"
+	if got := hex.EncodeToString(pbkdf2([]byte("passwd"), []byte("salt"), 1, 64)); got != want {
+		t.Errorf("expected %s, got %s", want, got)
+	}
+}
+
+func TestEncryptDecrypt(t *testing.T) {
+	k := testKey(t)
+
+	for _, val := range []string{"", "secret", "multi\nline", "ENC[not really]"} {
+		enc, err := k.Encrypt(val)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !IsEncrypted(enc) {
+			t.Fatalf("%s is not encrypted: %s", val, enc)
+		}
+
+		dec, err := k.Decrypt(enc)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if dec != val {
+			t.Errorf("expected \"%s\", got \"%s\"", val, dec)
+		}
+	}
+}
+
+func TestDecryptErrors(t *testing.T) {
+	k := testKey(t)
+
+	enc, err := k.Encrypt("secret")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	other, err := newKey(make([]byte, keySize))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name string
+		key  *Key
+		val  string
+		err  string
+	}{
+		{name: "plain value", key: k, val: "secret", err: "value is not encrypted"},
+		{name: "wrong key", key: other, val: enc, err: "wrong key"},
+		{name: "swapped type", key: k, val: strings.Replace(enc, "type:str", "type:int", 1), err: "wrong key"},
+		{name: "no key", val: enc, err: "key is required"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := tt.key.Decrypt(tt.val)
+			if err == nil || !strings.Contains(err.Error(), tt.err) {
+				t.Errorf("expected error containing \"%s\", got %v", tt.err, err)
+			}
+		})
+	}
+}
+
+func TestDecryptLegacy(t *testing.T) {
+	k := testKey(t)
+
+	// values encrypted before the type was added have no additional data.
+	iv := make([]byte, k.aead.NonceSize())
+	data := k.aead.Seal(nil, iv, []byte("secret"), nil)
+	legacy := "ENC[AES256_GCM,data:" + base64.StdEncoding.EncodeToString(data) + ",iv:" + base64.StdEncoding.EncodeToString(iv) + "]"
+
+	dec, err := k.Decrypt(legacy)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if dec != "secret" {
+		t.Errorf("expected \"secret\", got \"%s\"", dec)
+	}
+}
+
+func TestValues(t *testing.T) {
+	k := testKey(t)
+
+	doc := []byte(`
+str: value
+int: 42
+big: 18446744073709551615
+float: 1.5
+bool: true
+empty:
+nested:
+  list:
+    - item
+    - 7
+  map:
+    key: value
+`)
+
+	enc, err := k.EncryptValues(doc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, plain := range []string{"value", "42", "1.5", "true", "item"} {
+		if strings.Contains(string(enc), plain) {
+			t.Errorf("%s is not encrypted:\n%s", plain, enc)
+		}
+	}
+
+	// encrypted values are kept as is.
+	again, err := k.EncryptValues(enc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(again) != string(enc) {
+		t.Errorf("encrypted values are encrypted again:\n%s", again)
+	}
+
+	dec, err := k.DecryptValues(enc)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var want, got interface{}
+	if err := yaml.Unmarshal(doc, &want); err != nil {
+		t.Fatal(err)
+	}
+	if err := yaml.Unmarshal(dec, &got); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(want, got) {
+		t.Errorf("expected %v, got %v", want, got)
+	}
+
+	if _, err := k.DecryptValues(doc); err == nil {
+		t.Error("expected plain values to be rejected")
+	}
+
+	opened, err := k.OpenValues([]byte("plain: value\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(opened) != "plain: value\n" {
+		t.Errorf("expected plain values to be kept, got %s", opened)
+	}
+}
+
+func TestKeyFile(t *testing.T) {
+	dir, err := ioutil.TempDir("", "secrets")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "secrets.key")
+	if _, err := GenerateKey(path, ""); err == nil {
+		t.Fatal("expected passphrase to be required")
+	}
+
+	k, err := GenerateKey(path, "passphrase")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := GenerateKey(path, "passphrase"); err == nil {
+		t.Fatal("expected existing key not to be overwritten")
+	}
+
+	enc, err := k.Encrypt("secret")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := LoadKey(path, "wrong"); err == nil || !strings.Contains(err.Error(), "wrong passphrase") {
+		t.Fatalf("expected wrong passphrase error, got %v", err)
+	}
+
+	loaded, err := LoadKey(path, "passphrase")
+	if err != nil {
+		t.Fatal(err)
+	}
+	dec, err := loaded.Decrypt(enc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if dec != "secret" {
+		t.Errorf("expected \"secret\", got \"%s\"", dec)
+	}
+}
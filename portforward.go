@@ -0,0 +1,53 @@
+package mannequin
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+)
+
+// PortForward of the deployed resource to the local port.
+// Resource is the kubectl resource (e.g. svc/mysql), deployment of the release is used by default.
+type PortForward struct {
+	Resource string `yaml:"resource,omitempty"`
+	Local    int    `yaml:"local,omitempty"`
+	Remote   int    `yaml:"remote"`
+}
+
+// Validate the PortForward.
+func (pf PortForward) Validate() error {
+	switch {
+	case pf.Remote == 0:
+		return errors.New("remote port is required")
+	case pf.Remote < 0 || pf.Remote > 65535:
+		return fmt.Errorf("remote port %d is out of range", pf.Remote)
+	case pf.Local < 0 || pf.Local > 65535:
+		return fmt.Errorf("local port %d is out of range", pf.Local)
+	}
+
+	return nil
+}
+
+// Args of the kubectl command that forwards the port.
+// Remote port is used locally if the local one is not set.
+func (pf PortForward) Args(k8sCtx, namespace, release string) []string {
+	res := pf.Resource
+	if res == "" {
+		res = "deployment/" + release
+	}
+
+	local := pf.Local
+	if local == 0 {
+		local = pf.Remote
+	}
+
+	args := []string{"kubectl"}
+	if k8sCtx != "" {
+		args = append(args, "--context", k8sCtx)
+	}
+	if namespace != "" {
+		args = append(args, "--namespace", namespace)
+	}
+
+	return append(args, "port-forward", res, strconv.Itoa(local)+":"+strconv.Itoa(pf.Remote))
+}
@@ -0,0 +1,77 @@
+package mannequin
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/kostkobv/mannequin/pkg/docker"
+	"github.com/kostkobv/mannequin/pkg/helm"
+	"github.com/kostkobv/mannequin/pkg/kustomize"
+	"github.com/kostkobv/mannequin/pkg/manifests"
+)
+
+// DefaultProfile is applied if no other profile is selected.
+const DefaultProfile = "default"
+
+// Profile overrides parts of the LConfig.
+type Profile struct {
+	Docker docker.LConfig `yaml:"docker,omitempty,flow"`
+	Helm   helm.LConfig   `yaml:"helm,omitempty,flow"`
+
+	Kustomize kustomize.LConfig `yaml:"kustomize,omitempty,flow"`
+	Manifests manifests.LConfig `yaml:"manifests,omitempty,flow"`
+	Deps      Deps              `yaml:"deps,omitempty,flow"`
+	Vars      VarSources        `yaml:"vars,omitempty,flow"`
+}
+
+// ProfileNames returns sorted names of the available profiles.
+func (lc *LConfig) ProfileNames() []string {
+	names := make([]string, 0, len(lc.Profiles))
+	for n := range lc.Profiles {
+		names = append(names, n)
+	}
+	sort.Strings(names)
+
+	return names
+}
+
+// ApplyProfile overrides the LConfig with the profile of the provided name.
+// If no name is provided DefaultProfile is applied (if defined).
+func (lc *LConfig) ApplyProfile(name string) error {
+	if name == "" {
+		if _, ok := lc.Profiles[DefaultProfile]; !ok {
+			return nil
+		}
+		name = DefaultProfile
+	}
+
+	p, ok := lc.Profiles[name]
+	if !ok {
+		return fmt.Errorf("profile \"%s\" is not defined (available: %s)", name, strings.Join(lc.ProfileNames(), ", "))
+	}
+
+	lc.Docker.Merge(p.Docker)
+	lc.Helm.Merge(p.Helm)
+	lc.Kustomize.Merge(p.Kustomize)
+	lc.Manifests.Merge(p.Manifests)
+
+	// deps of the profile replace the deps of the project, so the profile
+	// could define lighter or heavier setup.
+	if p.Deps != nil {
+		lc.Deps = p.Deps
+	}
+
+	for k, v := range p.Vars.Values {
+		if lc.Vars.Values == nil {
+			lc.Vars.Values = map[string]string{}
+		}
+		lc.Vars.Values[k] = v
+	}
+	lc.Vars.Files = append(lc.Vars.Files, p.Vars.Files...)
+	lc.Vars.Env = append(lc.Vars.Env, p.Vars.Env...)
+
+	lc.Profile = name
+
+	return nil
+}
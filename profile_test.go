@@ -0,0 +1,147 @@
+package mannequin
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/kostkobv/mannequin/pkg/helm"
+
+	"gopkg.in/yaml.v2"
+)
+
+const profilesDoc = `
+version: v0.1.0
+name: app
+helm:
+  chart: chart
+  release_name: app
+  values: values.yaml
+  inline_values:
+    image:
+      pullPolicy: Always
+      tag: latest
+    replicas: 1
+deps:
+  - name: db
+    type: service
+  - name: api
+    type: project
+vars:
+  values:
+    A: "1"
+profiles:
+  default:
+    helm:
+      namespace: default-ns
+  light:
+    helm:
+      values:
+        - path: light.yaml
+          optional: true
+      inline_values:
+        image:
+          tag: light
+    deps:
+      - name: db
+        type: service
+    vars:
+      values:
+        B: "2"
+  none:
+    deps: []
+`
+
+func readProfiles(t *testing.T) LConfig {
+	t.Helper()
+
+	var lc LConfig
+	if err := yaml.UnmarshalStrict([]byte(profilesDoc), &lc); err != nil {
+		t.Fatal(err)
+	}
+
+	return lc
+}
+
+func TestApplyProfile(t *testing.T) {
+	lc := readProfiles(t)
+	if err := lc.ApplyProfile("light"); err != nil {
+		t.Fatal(err)
+	}
+
+	if lc.Profile != "light" {
+		t.Errorf("expected profile \"light\", got \"%s\"", lc.Profile)
+	}
+
+	// deps are replaced.
+	if want := (Deps{{Name: "db", Type: DepService}}); !reflect.DeepEqual(lc.Deps, want) {
+		t.Errorf("expected deps %+v, got %+v", want, lc.Deps)
+	}
+
+	// values files are appended.
+	if want := (helm.ValuesFiles{{Path: "values.yaml"}, {Path: "light.yaml", Optional: true}}); !reflect.DeepEqual(lc.Helm.Values, want) {
+		t.Errorf("expected values %+v, got %+v", want, lc.Helm.Values)
+	}
+
+	// inline values are merged deeply.
+	want := map[string]interface{}{
+		"image":    map[interface{}]interface{}{"pullPolicy": "Always", "tag": "light"},
+		"replicas": 1,
+	}
+	if !reflect.DeepEqual(lc.Helm.InlineValues, want) {
+		t.Errorf("expected inline values %v, got %v", want, lc.Helm.InlineValues)
+	}
+
+	// vars are merged.
+	if want := map[string]string{"A": "1", "B": "2"}; !reflect.DeepEqual(lc.Vars.Values, want) {
+		t.Errorf("expected vars %v, got %v", want, lc.Vars.Values)
+	}
+
+	// the other profiles are not applied.
+	if lc.Helm.Namespace != "" {
+		t.Errorf("expected namespace of the default profile not to be applied, got \"%s\"", lc.Helm.Namespace)
+	}
+}
+
+func TestApplyProfileDefault(t *testing.T) {
+	lc := readProfiles(t)
+	if err := lc.ApplyProfile(""); err != nil {
+		t.Fatal(err)
+	}
+	if lc.Profile != DefaultProfile || lc.Helm.Namespace != "default-ns" {
+		t.Errorf("expected the default profile to be applied, got \"%s\" (%s)", lc.Profile, lc.Helm.Namespace)
+	}
+
+	// nothing is applied without the default profile.
+	lc = readProfiles(t)
+	delete(lc.Profiles, DefaultProfile)
+	before := readProfiles(t)
+	delete(before.Profiles, DefaultProfile)
+	if err := lc.ApplyProfile(""); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(lc, before) {
+		t.Errorf("expected nothing to be applied, got %+v", lc)
+	}
+}
+
+func TestApplyProfileEmptyDeps(t *testing.T) {
+	lc := readProfiles(t)
+	if err := lc.ApplyProfile("none"); err != nil {
+		t.Fatal(err)
+	}
+	if len(lc.Deps) != 0 {
+		t.Errorf("expected deps to be replaced with none, got %+v", lc.Deps)
+	}
+}
+
+func TestApplyProfileUndefined(t *testing.T) {
+	lc := readProfiles(t)
+	err := lc.ApplyProfile("heavy")
+	if err == nil {
+		t.Fatal("expected undefined profile to be rejected")
+	}
+	if !strings.Contains(err.Error(), "available: default, light, none") {
+		t.Errorf("expected available profiles to be listed, got %s", err)
+	}
+}
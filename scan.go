@@ -0,0 +1,61 @@
+package mannequin
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// dirs that never contain the projects.
+var skipDirs = map[string]bool{"node_modules": true, "vendor": true}
+
+// FindProjects walks the dir tree and returns the Projects with the local configuration.
+// Hidden dirs, node_modules and vendor dirs are skipped.
+// Project is named after the name of it's local configuration.
+// Local configurations that couldn't be read (unreadable, invalid, written by the newer version)
+// are skipped and returned as the problems. Nothing is written.
+func FindProjects(root string) ([]Project, []error, error) {
+	root, err := filepath.Abs(root)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var (
+		res      []Project
+		problems []error
+	)
+	err = filepath.Walk(root, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			// unreadable dirs are skipped.
+			if info != nil && info.IsDir() && path != root {
+				return filepath.SkipDir
+			}
+			return err
+		}
+		if !info.IsDir() {
+			return nil
+		}
+		if path != root && (strings.HasPrefix(info.Name(), ".") || skipDirs[info.Name()]) {
+			return filepath.SkipDir
+		}
+
+		p := Project{Path: path}
+		if !p.Exists() {
+			return nil
+		}
+
+		lc, err := NewLConfigFromPath(p.LConfigPath())
+		if err != nil {
+			problems = append(problems, fmt.Errorf("%s: %s", p.LConfigPath(), err))
+			return nil
+		}
+
+		p.Name = lc.Name
+		res = append(res, p)
+
+		return nil
+	})
+
+	return res, problems, err
+}
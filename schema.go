@@ -0,0 +1,85 @@
+package mannequin
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+)
+
+// SchemaURL is the location of the published JSON Schema of the local configuration.
+const SchemaURL = "https://raw.githubusercontent.com/kostkobv/mannequin/master/schema/mnqn.schema.json"
+
+// JSONSchemer is implemented by the types that describe their own JSON Schema
+// (e.g. the ones with the custom yaml unmarshaling).
+type JSONSchemer interface {
+	JSONSchema() map[string]interface{}
+}
+
+var schemerType = reflect.TypeOf((*JSONSchemer)(nil)).Elem()
+
+// LConfigSchema returns JSON Schema of the local configuration.
+// Used by the editors for the autocompletion and validation of .mnqn.yaml.
+func LConfigSchema() ([]byte, error) {
+	s := schemaOf(reflect.TypeOf(LConfig{}))
+	s["$schema"] = "http://json-schema.org/draft-07/schema#"
+	s["$id"] = SchemaURL
+	s["title"] = "Mannequin local configuration (" + DefaultLConfigFileName + ")"
+	s["required"] = []string{"version", "name"}
+
+	return json.MarshalIndent(s, "", "  ")
+}
+
+func schemaOf(t reflect.Type) map[string]interface{} {
+	if t.Implements(schemerType) {
+		return reflect.Zero(t).Interface().(JSONSchemer).JSONSchema()
+	}
+	if reflect.PtrTo(t).Implements(schemerType) {
+		return reflect.New(t).Interface().(JSONSchemer).JSONSchema()
+	}
+
+	switch t.Kind() {
+	case reflect.Ptr:
+		return schemaOf(t.Elem())
+	case reflect.String:
+		return map[string]interface{}{"type": "string"}
+	case reflect.Bool:
+		return map[string]interface{}{"type": "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return map[string]interface{}{"type": "integer"}
+	case reflect.Float32, reflect.Float64:
+		return map[string]interface{}{"type": "number"}
+	case reflect.Slice, reflect.Array:
+		return map[string]interface{}{"type": "array", "items": schemaOf(t.Elem())}
+	case reflect.Map:
+		return map[string]interface{}{"type": "object", "additionalProperties": schemaOf(t.Elem())}
+	case reflect.Struct:
+		props := map[string]interface{}{}
+		for i := 0; i < t.NumField(); i++ {
+			f := t.Field(i)
+			if f.PkgPath != "" || f.Type.Kind() == reflect.Func {
+				continue
+			}
+
+			name := strings.Split(f.Tag.Get("yaml"), ",")[0]
+			switch name {
+			case "-":
+				continue
+			case "":
+				name = strings.ToLower(f.Name)
+			}
+
+			props[name] = schemaOf(f.Type)
+		}
+
+		return map[string]interface{}{"type": "object", "properties": props, "additionalProperties": false}
+	}
+
+	// interface{} and the rest accept anything.
+	return map[string]interface{}{}
+}
+
+// JSONSchema impl.
+func (t DepType) JSONSchema() map[string]interface{} {
+	return map[string]interface{}{"type": "string", "enum": validDepTypes}
+}
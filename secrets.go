@@ -0,0 +1,189 @@
+package mannequin
+
+import (
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/kostkobv/mannequin/pkg"
+	"github.com/kostkobv/mannequin/pkg/secrets"
+
+	"gopkg.in/yaml.v2"
+)
+
+const (
+	secretsKeyFile = "secrets.key"
+
+	// SecretsPassphraseEnv is the environment variable the passphrase of the secrets key is taken from.
+	SecretsPassphraseEnv = "MNQN_SECRETS_PASSPHRASE"
+)
+
+// SecretsKeyPath returns expected path for the secrets key.
+func SecretsKeyPath() (string, error) {
+	folderPath, err := ConfigFolderPath()
+	if err != nil {
+		return "", err
+	}
+
+	return filepath.Join(folderPath, secretsKeyFile), nil
+}
+
+// SecretsKey returns the key that is used to encrypt and decrypt the secrets.
+// The key is decrypted with the passphrase taken from SecretsPassphraseEnv or asked for.
+// If there is no key yet and create is true - the new one is generated.
+func (m Mnqn) SecretsKey(create bool) (*secrets.Key, error) {
+	path, err := SecretsKeyPath()
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = os.Stat(path)
+	switch {
+	case os.IsNotExist(err) && create:
+		fmt.Fprintf(m, "Generating the secrets key at %s.\n", path)
+		pass, err := m.passphrase(true)
+		if err != nil {
+			return nil, err
+		}
+		return secrets.GenerateKey(path, pass)
+	case os.IsNotExist(err):
+		return nil, fmt.Errorf("secrets key is not found at %s", path)
+	case err != nil:
+		return nil, fmt.Errorf("couldn't read secrets key: %s", err)
+	}
+
+	pass, err := m.passphrase(false)
+	if err != nil {
+		return nil, err
+	}
+
+	k, err := secrets.LoadKey(path, pass)
+	if err != nil {
+		return nil, fmt.Errorf("couldn't read secrets key: %s", err)
+	}
+
+	return k, nil
+}
+
+// passphrase of the secrets key. Asked twice if it is a new one.
+func (m Mnqn) passphrase(confirm bool) (string, error) {
+	if pass := os.Getenv(SecretsPassphraseEnv); pass != "" {
+		return pass, nil
+	}
+
+	fmt.Fprintf(m, "Please provide the passphrase of the secrets key (or set %s):\n", SecretsPassphraseEnv)
+	pass, err := m.ReadLine()
+	if err != nil {
+		return "", fmt.Errorf("couldn't read passphrase: %s", err)
+	}
+	if pass == "" {
+		return "", errors.New("passphrase is required")
+	}
+
+	if !confirm {
+		return pass, nil
+	}
+
+	fmt.Fprintln(m, "Please repeat the passphrase:")
+	again, err := m.ReadLine()
+	if err != nil {
+		return "", fmt.Errorf("couldn't read passphrase: %s", err)
+	}
+	if again != pass {
+		return "", errors.New("passphrases don't match")
+	}
+
+	return pass, nil
+}
+
+// HasSecrets returns true if the LConfig refers to any encrypted values.
+func (lc *LConfig) HasSecrets() bool {
+	return len(lc.Secrets) != 0 || lc.Helm.SecretsPath != ""
+}
+
+// RevealSecrets decrypts the secrets of the LConfig in memory.
+// Secret values are registered as variables and the helm secret values file
+// is decrypted into the helm configuration.
+func (lc *LConfig) RevealSecrets(k *secrets.Key, vars pkg.VarStorer) error {
+	switch {
+	case k == nil:
+		return errors.New("key is required")
+	case vars == nil:
+		return errors.New("variable store is required")
+	}
+
+	names := make([]string, 0, len(lc.Secrets))
+	for n := range lc.Secrets {
+		names = append(names, n)
+	}
+	sort.Strings(names)
+
+	for _, n := range names {
+		val, err := k.Decrypt(lc.Secrets[n])
+		if err != nil {
+			return fmt.Errorf("secret \"%s\": %s", n, err)
+		}
+
+		if err := vars.Register(n, val); err != nil {
+			return err
+		}
+	}
+
+	if lc.Helm.SecretsPath == "" {
+		return nil
+	}
+
+	doc, err := ioutil.ReadFile(lc.Helm.SecretsPath)
+	if err != nil {
+		return fmt.Errorf("couldn't read helm secrets: %s", err)
+	}
+
+	if lc.Helm.SecretValues, err = k.DecryptValues(doc); err != nil {
+		return fmt.Errorf("couldn't decrypt helm secrets: %s", err)
+	}
+
+	return nil
+}
+
+// SecretValues returns the decrypted string values of the secrets (see RevealSecrets),
+// so they could be masked in the output. Numbers and booleans are not secret enough to be masked.
+func (lc *LConfig) SecretValues(vars pkg.VarStorer) []string {
+	var res []string
+	for n := range lc.Secrets {
+		if v, err := vars.Var(n); err == nil {
+			res = append(res, v)
+		}
+	}
+
+	var doc interface{}
+	if err := yaml.Unmarshal(lc.Helm.SecretValues, &doc); err == nil {
+		res = append(res, scalars(doc)...)
+	}
+
+	return res
+}
+
+// scalars returns the string values of the yaml document.
+func scalars(doc interface{}) []string {
+	switch v := doc.(type) {
+	case map[interface{}]interface{}:
+		var res []string
+		for _, vv := range v {
+			res = append(res, scalars(vv)...)
+		}
+		return res
+	case []interface{}:
+		var res []string
+		for _, vv := range v {
+			res = append(res, scalars(vv)...)
+		}
+		return res
+	case string:
+		return []string{v}
+	}
+
+	return nil
+}
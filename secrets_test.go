@@ -0,0 +1,27 @@
+package mannequin
+
+import (
+	"reflect"
+	"sort"
+	"testing"
+)
+
+func TestSecretValues(t *testing.T) {
+	lc := LConfig{
+		Secrets: map[string]string{"DB_PASSWORD": "ENC[...]", "MISSING": "ENC[...]"},
+	}
+	lc.Helm.SecretValues = []byte("db:\n  password: p4ssw0rd\n  port: 5432\n  tls: true\ntokens:\n  - t0k3n\n  - 1.5\nempty:\n")
+
+	vars := LocalVars{}
+	if err := vars.Register("DB_PASSWORD", "s3cr3t"); err != nil {
+		t.Fatal(err)
+	}
+
+	got := lc.SecretValues(&vars)
+	sort.Strings(got)
+
+	// numbers and booleans are not masked.
+	if want := []string{"p4ssw0rd", "s3cr3t", "t0k3n"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("expected %v, got %v", want, got)
+	}
+}
@@ -0,0 +1,356 @@
+package mannequin
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/kostkobv/mannequin/pkg/docker"
+	"github.com/kostkobv/mannequin/pkg/helm"
+	"github.com/kostkobv/mannequin/pkg/kustomize"
+	"github.com/kostkobv/mannequin/pkg/manifests"
+
+	"gopkg.in/yaml.v2"
+)
+
+var (
+	yamlErrLine = regexp.MustCompile(`^(?:yaml: )?line (\d+): (.*)$`)
+	unknownKey  = regexp.MustCompile(`^field (\S+) not found in type .*$`)
+	// varRef matches $NAME and ${NAME} references.
+	varRef = regexp.MustCompile(`\$(?:\{([A-Za-z_][A-Za-z0-9_]*)\}|([A-Za-z_][A-Za-z0-9_]*))`)
+
+	// vars that are set by Mannequin itself.
+	builtinVars = []string{docker.VarDockerImageTag, docker.VarDockerImageName, docker.VarDockerImageVersion}
+)
+
+// Problem of the configuration.
+type Problem struct {
+	Line int
+	Path string
+	Msg  string
+}
+
+// Error impl.
+func (p Problem) Error() string {
+	var res string
+	if p.Line != 0 {
+		res = "line " + strconv.Itoa(p.Line) + ": "
+	}
+	if p.Path != "" {
+		res += p.Path + ": "
+	}
+
+	return res + p.Msg
+}
+
+// Problems is a list of all the found Problems.
+type Problems []Problem
+
+// Error impl.
+func (ps Problems) Error() string {
+	res := make([]string, len(ps))
+	for i, p := range ps {
+		res[i] = p.Error()
+	}
+
+	return strings.Join(res, "; ")
+}
+
+// Err returns nil if there are no Problems.
+func (ps Problems) Err() error {
+	if len(ps) == 0 {
+		return nil
+	}
+
+	return ps
+}
+
+// ValidateLConfigFile reports every problem of the local configuration file at once:
+// syntax errors, unknown keys, missing files and dirs, invalid dependencies and
+// references to undefined variables.
+// Relative paths are resolved against the dir of the file.
+// Dependencies of the project type are checked against the registered projects of cfg (if provided).
+func ValidateLConfigFile(path string, cfg *Config) (Problems, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	v := validator{data: data, dir: filepath.Dir(path), cfg: cfg}
+	v.run()
+
+	sort.SliceStable(v.ps, func(i, j int) bool {
+		return v.ps[i].Line < v.ps[j].Line
+	})
+
+	return v.ps, nil
+}
+
+type validator struct {
+	data []byte
+	dir  string
+	cfg  *Config
+	ps   Problems
+}
+
+func (v *validator) run() {
+	var lc LConfig
+	if err := yaml.UnmarshalStrict(v.data, &lc); err != nil {
+		te, ok := err.(*yaml.TypeError)
+		if !ok {
+			// syntax errors make the rest of the checks pointless.
+			v.yamlErr(err.Error())
+			return
+		}
+
+		for _, e := range te.Errors {
+			v.yamlErr(e)
+		}
+
+		lc = LConfig{}
+		if err := yaml.Unmarshal(v.data, &lc); err != nil {
+			return
+		}
+	}
+
+	if lc.Version != "" {
+		cmp, err := compareVers(lc.Version, ver)
+		switch {
+		case err != nil:
+			v.add(err.Error(), "version")
+		case cmp > 0:
+			v.add(fmt.Sprintf("written by newer mnqnctl (%s, current is %s)", lc.Version, ver), "version")
+		}
+	}
+
+	if err := lc.Validate(); err != nil {
+		for _, p := range err.(Problems) {
+			v.add(p.Msg, strings.Split(p.Path, ".")...)
+		}
+	}
+
+	v.section(nil, lc.Docker, lc.Helm, lc.Kustomize, lc.Manifests, lc.Vars, lc.Deps)
+	for _, n := range lc.ProfileNames() {
+		p := lc.Profiles[n]
+		v.section([]string{"profiles", n}, p.Docker, p.Helm, p.Kustomize, p.Manifests, p.Vars, p.Deps)
+	}
+
+	v.vars(lc)
+}
+
+// section checks the files and dependencies of the root configuration or of the profile.
+func (v *validator) section(prefix []string, dc docker.LConfig, hc helm.LConfig, kc kustomize.LConfig, mc manifests.LConfig, vs VarSources, deps Deps) {
+	keys := func(k ...string) []string {
+		return append(append([]string{}, prefix...), k...)
+	}
+
+	// Dockerfile is required in the root configuration only.
+	if dc.File != "" || prefix == nil {
+		file := dc.File
+		if file == "" {
+			file = docker.DefaultFilePath
+		}
+		v.file(file, false, keys("docker", "file")...)
+	}
+
+	if hc.ChartPath != "" && !hc.IsOCI() {
+		v.file(hc.ChartPath, true, keys("helm", "chart")...)
+	}
+	for _, vf := range hc.Values {
+		if !vf.Optional {
+			v.fileItem(vf.Path, keys("helm", "values")...)
+		}
+	}
+	if hc.SecretsPath != "" {
+		v.file(hc.SecretsPath, false, keys("helm", "secrets")...)
+	}
+	if kc.Path != "" {
+		v.file(kc.Path, true, keys("kustomize", "path")...)
+	}
+	for _, p := range kc.Patches {
+		v.fileItem(p, keys("kustomize", "patches")...)
+	}
+	if mc.Path != "" {
+		v.file(mc.Path, true, keys("manifests", "path")...)
+	}
+	for _, f := range vs.Files {
+		v.fileItem(f, keys("vars", "files")...)
+	}
+
+	for _, d := range deps {
+		k := keys("deps", d.Name)
+		switch {
+		case d.Name == "":
+			v.add("dependency name is required", keys("deps")...)
+			continue
+		case d.Type == "":
+			v.add("dependency type is required", k...)
+			continue
+		}
+
+		var validType bool
+		for _, t := range validDepTypes {
+			validType = validType || t == d.Type
+		}
+		if !validType {
+			v.add(fmt.Sprintf("%s is not a valid dep type (expected one of: %s)", d.Type, joinDepTypes()), k...)
+			continue
+		}
+
+		if d.Type == DepProject && v.cfg != nil {
+			if _, err := v.cfg.Project(d.Name); err != nil {
+				v.add(err.Error(), k...)
+			}
+		}
+	}
+}
+
+// vars checks if every referenced variable is defined.
+func (v *validator) vars(lc LConfig) {
+	known := map[string]bool{}
+	for _, n := range builtinVars {
+		known[n] = true
+	}
+	collect := func(vs VarSources) {
+		for n := range vs.Values {
+			known[n] = true
+		}
+		for _, n := range vs.Env {
+			known[n] = true
+		}
+		for _, f := range vs.Files {
+			vars, err := ReadEnvFile(v.abs(f))
+			if err != nil {
+				continue
+			}
+			for _, kv := range vars {
+				known[kv[0]] = true
+			}
+		}
+	}
+
+	collect(lc.Vars)
+	for n := range lc.Secrets {
+		known[n] = true
+	}
+	for _, p := range lc.Profiles {
+		collect(p.Vars)
+	}
+
+	for i, l := range strings.Split(string(v.data), "\n") {
+		// comments are not expanded (e.g. `# yaml-language-server: $schema=...`).
+		if strings.HasPrefix(strings.TrimSpace(l), "#") {
+			continue
+		}
+
+		for _, ref := range varRef.FindAllStringSubmatch(l, -1) {
+			name := ref[1] + ref[2]
+			if known[name] {
+				continue
+			}
+			known[name] = true
+
+			v.problem(Problem{
+				Line: i + 1,
+				Msg:  fmt.Sprintf("variable \"%s\" is not defined", name),
+			})
+		}
+	}
+}
+
+func (v *validator) file(path string, dir bool, keys ...string) {
+	if msg := v.checkPath(path, dir); msg != "" {
+		v.add(msg, keys...)
+	}
+}
+
+// fileItem checks the file that is an item of the list.
+func (v *validator) fileItem(path string, keys ...string) {
+	if msg := v.checkPath(path, false); msg != "" {
+		v.problem(Problem{Line: v.locate(append(keys, path)), Path: strings.Join(keys, "."), Msg: msg})
+	}
+}
+
+func (v *validator) checkPath(path string, dir bool) string {
+	i, err := os.Stat(v.abs(path))
+	switch {
+	case os.IsNotExist(err):
+		return fmt.Sprintf("\"%s\" is not found", path)
+	case err != nil:
+		return err.Error()
+	case dir && !i.IsDir():
+		return fmt.Sprintf("\"%s\" is expected to be a dir", path)
+	case !dir && i.IsDir():
+		return fmt.Sprintf("\"%s\" is expected to be a file", path)
+	}
+
+	return ""
+}
+
+func (v *validator) abs(path string) string {
+	if filepath.IsAbs(path) {
+		return path
+	}
+
+	return filepath.Join(v.dir, path)
+}
+
+func (v *validator) yamlErr(msg string) {
+	res := yamlErrLine.FindStringSubmatch(msg)
+	if len(res) < 3 {
+		v.problem(Problem{Msg: msg})
+		return
+	}
+
+	line, _ := strconv.Atoi(res[1])
+	msg = unknownKey.ReplaceAllString(res[2], `unknown key "$1"`)
+	v.problem(Problem{Line: line, Msg: msg})
+}
+
+// add the problem of the value found by the keys.
+func (v *validator) add(msg string, keys ...string) {
+	v.problem(Problem{Line: v.locate(keys), Path: strings.Join(keys, "."), Msg: msg})
+}
+
+func (v *validator) problem(p Problem) {
+	v.ps = append(v.ps, p)
+}
+
+// locate returns the line of the value by following the keys.
+// Lines are looked up sequentially, so both block and flow styles are supported.
+// Returns the line of the deepest found key.
+func (v *validator) locate(keys []string) int {
+	lines := strings.Split(string(v.data), "\n")
+
+	var line, start int
+	for _, k := range keys {
+		re := regexp.MustCompile(`(^|[\s{,\-])["']?` + regexp.QuoteMeta(k) + `["']?\s*(:|,|\]|}|$)`)
+
+		found := false
+		for i := start; i < len(lines); i++ {
+			if re.MatchString(lines[i]) {
+				start, line, found = i, i+1, true
+				break
+			}
+		}
+		if !found {
+			break
+		}
+	}
+
+	return line
+}
+
+func joinDepTypes() string {
+	res := make([]string, len(validDepTypes))
+	for i, t := range validDepTypes {
+		res[i] = string(t)
+	}
+
+	return strings.Join(res, ", ")
+}
@@ -0,0 +1,97 @@
+package mannequin
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestValidateLConfigFile(t *testing.T) {
+	dir, err := ioutil.TempDir("", "validate")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	if err := ioutil.WriteFile(filepath.Join(dir, "Dockerfile"), []byte("FROM scratch\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Mkdir(filepath.Join(dir, "chart"), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg := &Config{Version: ver, Projects: []Project{{Name: "api", Path: dir}}}
+	head := "version: " + ver + "\nname: app\nhelm:\n  chart: chart\n  release_name: app\n"
+
+	tests := []struct {
+		name string
+		doc  string
+		want []string
+	}{
+		{
+			name: "valid",
+			doc: "# yaml-language-server: $schema=mnqn.schema.json\n" + head +
+				"  set:\n    image.tag: $DOCKER_IMAGE_VERSION\n    host: ${HOST}\n" +
+				"deps:\n  - name: api\n    type: project\n  - name: db\n    type: service\n" +
+				"vars:\n  values:\n    HOST: localhost\n",
+		},
+		{
+			name: "unknown keys",
+			doc:  head + "  unknown: true\nunknown_root: true\n",
+			want: []string{"line 6: unknown key \"unknown\"", "line 7: unknown key \"unknown_root\""},
+		},
+		{
+			name: "missing files and dirs",
+			doc: "version: " + ver + "\nname: app\ndocker:\n  file: missing.Dockerfile\nhelm:\n  chart: Dockerfile\n  release_name: app\n" +
+				"  values:\n    - missing.yaml\n    - path: optional.yaml\n      optional: true\n",
+			want: []string{
+				"line 4: docker.file: \"missing.Dockerfile\" is not found",
+				"line 6: helm.chart: \"Dockerfile\" is expected to be a dir",
+				"line 9: helm.values: \"missing.yaml\" is not found",
+			},
+		},
+		{
+			name: "bad dep type",
+			doc:  head + "deps:\n  - name: db\n    type: database\n",
+			want: []string{"line 7: deps.db: database is not a valid dep type (expected one of: project, service)"},
+		},
+		{
+			name: "unregistered dep",
+			doc:  head + "deps:\n  - name: other\n    type: project\n",
+			want: []string{"line 7: deps.other: project \"other\" is not registered"},
+		},
+		{
+			name: "undefined variables",
+			doc:  head + "  set:\n    a: $UNDEFINED\n    b: ${BRACED}-$UNDEFINED\n",
+			want: []string{"line 7: variable \"UNDEFINED\" is not defined", "line 8: variable \"BRACED\" is not defined"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(dir, ".mnqn.yaml")
+			if err := ioutil.WriteFile(path, []byte(tt.doc), 0644); err != nil {
+				t.Fatal(err)
+			}
+
+			ps, err := ValidateLConfigFile(path, cfg)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			var got []string
+			for _, p := range ps {
+				got = append(got, p.Error())
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("expected %q, got %q", tt.want, got)
+			}
+		})
+	}
+
+	if _, err := ValidateLConfigFile(filepath.Join(dir, "missing.yaml"), cfg); err == nil {
+		t.Error("expected missing configuration to fail")
+	}
+}
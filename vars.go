@@ -0,0 +1,134 @@
+package mannequin
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/kostkobv/mannequin/pkg/docker"
+)
+
+// VarSources represents the sources the LocalVars are imported from.
+// Sources are applied in the following order, so the latter one overrides
+// the former one:
+//  1. literal values;
+//  2. .env files (in the order they are listed);
+//  3. OS environment variables.
+type VarSources struct {
+	Values map[string]string `yaml:"values,omitempty,flow"`
+	Files  []string          `yaml:"files,omitempty,flow"`
+	Env    []string          `yaml:"env,omitempty,flow"`
+}
+
+// Import the variables from the provided sources.
+func (lv *LocalVars) Import(vs VarSources) error {
+	if lv == nil {
+		return errors.New("local variables are not defined")
+	}
+
+	names := make([]string, 0, len(vs.Values))
+	for k := range vs.Values {
+		names = append(names, k)
+	}
+	sort.Strings(names)
+	for _, k := range names {
+		if err := lv.Register(k, vs.Values[k]); err != nil {
+			return err
+		}
+	}
+
+	for _, path := range vs.Files {
+		vars, err := ReadEnvFile(path)
+		if err != nil {
+			return fmt.Errorf("couldn't read env file \"%s\": %s", path, err)
+		}
+
+		for _, v := range vars {
+			if err := lv.Register(v[0], v[1]); err != nil {
+				return err
+			}
+		}
+	}
+
+	for _, name := range vs.Env {
+		// unset variables keep the value from the previous sources.
+		val, ok := os.LookupEnv(name)
+		if !ok {
+			continue
+		}
+
+		if err := lv.Register(name, val); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// ReadEnvFile parses .env file and returns the name-value pairs
+// in the order they are defined.
+// Empty lines and lines starting with # are skipped.
+// Optional `export` prefix and quotes around values are stripped.
+func ReadEnvFile(path string) ([][2]string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var res [][2]string
+	s := bufio.NewScanner(f)
+	for n := 1; s.Scan(); n++ {
+		line := strings.TrimSpace(s.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		line = strings.TrimSpace(strings.TrimPrefix(line, "export "))
+
+		i := strings.Index(line, "=")
+		if i < 1 {
+			return nil, fmt.Errorf("line %d: expected NAME=value", n)
+		}
+
+		name := strings.TrimSpace(line[:i])
+		val := strings.TrimSpace(line[i+1:])
+		if len(val) > 1 && (val[0] == '"' || val[0] == '\'') && val[len(val)-1] == val[0] {
+			val = val[1 : len(val)-1]
+		}
+
+		res = append(res, [2]string{name, val})
+	}
+
+	return res, s.Err()
+}
+
+// ResolveVars imports the variables of the local configuration, decrypts the secrets
+// and registers the variables of the image, the same way it's done for the deployment.
+func (m *Mnqn) ResolveVars(lc *LConfig) error {
+	if err := m.LocalVars.Import(lc.Vars); err != nil {
+		return fmt.Errorf("couldn't import variables: %s", err)
+	}
+
+	if lc.HasSecrets() {
+		k, err := m.SecretsKey(false)
+		if err != nil {
+			return err
+		}
+
+		if err := lc.RevealSecrets(k, &m.LocalVars); err != nil {
+			return fmt.Errorf("couldn't decrypt secrets: %s", err)
+		}
+	}
+
+	if err := lc.Docker.GenerateImageName(lc.Name); err != nil {
+		return fmt.Errorf("couldn't generage image name: %s", err)
+	}
+	if err := lc.Docker.GenerateVer(); err != nil {
+		return fmt.Errorf("couldn't generate image version: %s", err)
+	}
+
+	return docker.RegisterVars(&m.LocalVars, lc.Docker)
+}